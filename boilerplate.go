@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// defaultBoilerplateMinMatches is how many different boilerplate patterns a description
+// must match to be flagged, unless boilerplate_min_matches says otherwise. One stock
+// phrase is common in human writing; several together rarely are.
+const defaultBoilerplateMinMatches = 2
+
+// defaultBoilerplatePatterns match stock phrasing common in generated PR descriptions.
+// They are case-insensitive, and boilerplate_patterns adds to them.
+var defaultBoilerplatePatterns = []string{
+	`this (?:pr|pull request|change) introduces an? (?:comprehensive|robust|holistic)`,
+	`\bcomprehensive (?:solution|overhaul|refactor(?:ing)?|implementation|approach)\b`,
+	`\bseamless(?:ly)?\b`,
+	`\brobust and scalable\b`,
+	`\benhanc(?:e|es|ing) the overall (?:user experience|maintainability|code quality|reliability)\b`,
+	`\bthis (?:pr|pull request|change) (?:ensures|aims to)\b`,
+	`\bin summary,`,
+	`\bdelv(?:e|es|ing)\b`,
+	`\bleverag(?:e|es|ing)\b`,
+	`\bstreamlin(?:e|es|ed|ing)\b`,
+	`\bkey (?:changes|improvements|features)\s*:`,
+}
+
+// compileBoilerplatePatterns compiles the default boilerplate patterns followed by the
+// user's, all case-insensitive
+func compileBoilerplatePatterns(extra []string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, pattern := range defaultBoilerplatePatterns {
+		patterns = append(patterns, regexp.MustCompile("(?i)"+pattern))
+	}
+	for _, pattern := range extra {
+		if pattern == "" {
+			return nil, fmt.Errorf("boilerplate_patterns cannot contain an empty pattern")
+		}
+		compiled, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boilerplate_patterns entry '%s': %w", pattern, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+	return patterns, nil
+}
+
+// boilerplateMatches returns how many of the patterns match text
+func boilerplateMatches(text string, patterns []*regexp.Regexp) int {
+	matches := 0
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			matches++
+		}
+	}
+	return matches
+}
+
+// looksLikeBoilerplate reports whether text matches at least minMatches of the patterns
+func looksLikeBoilerplate(text string, patterns []*regexp.Regexp, minMatches int) bool {
+	return boilerplateMatches(text, patterns) >= minMatches
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLooksLikeBoilerplate(t *testing.T) {
+	defaults, err := compileBoilerplatePatterns(nil)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected bool
+	}{
+		{"plain description", "Fix the off-by-one in pagination so the last page isn't dropped.", false},
+		{"one stock phrase", "Streamline the retry loop by removing the extra sleep.", false},
+		{"several stock phrases", "This PR introduces a comprehensive solution that seamlessly integrates caching. In summary, it enhances the overall user experience.", true},
+		{"case-insensitive", "KEY CHANGES: leveraging the new API to STREAMLINE uploads.", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, looksLikeBoilerplate(tt.text, defaults, defaultBoilerplateMinMatches))
+		})
+	}
+
+	t.Run("custom patterns and threshold", func(t *testing.T) {
+		patterns, err := compileBoilerplatePatterns([]string{`game[- ]changer`})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, boilerplateMatches("A real game-changer.", patterns))
+		assert.True(t, looksLikeBoilerplate("A real game-changer.", patterns, 1))
+
+		_, err = compileBoilerplatePatterns([]string{"("})
+		assert.ErrorContains(t, err, "invalid boilerplate_patterns entry '('")
+	})
+}
+
+func TestBoilerplateNote(t *testing.T) {
+	pr := PullRequestInfo{
+		Repository: "owner/repo", Title: "Caching", URL: "https://github.com/owner/repo/pull/1",
+		Description: "This PR introduces a comprehensive solution that seamlessly adds caching.",
+	}
+	render := func(flag bool) string {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, FlagBoilerplate: flag}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		var buf strings.Builder
+		writePRBlock(&buf, pr, 2, false, config)
+		return buf.String()
+	}
+
+	assert.Contains(t, render(true), "*Note: this description reads like generated boilerplate")
+	assert.NotContains(t, render(false), "generated boilerplate", "off by default")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultBragPrompt asks for the brag document: one-line accomplishments rather than prose
+const defaultBragPrompt = `An engineer keeps a brag document: a running list of their accomplishments, to draw on in performance reviews and promotion cases.
+List their accomplishments based on the PR descriptions in %s as a flat Markdown bulleted list. Put each accomplishment on a single line starting with "- ", lead with its outcome or impact, and end it with links to the PRs it covers. Combine closely related PRs into one bullet.
+Output only the list, with no headings, introduction, or nested bullets. Don't write any files.`
+
+// bragPrompt returns the base prompt for brag.md. A custom brag_prompt is taken literally,
+// with a sentence pointing at the PR descriptions added after it.
+func bragPrompt(config Config) string {
+	if config.BragPrompt == "" {
+		return defaultBragPrompt
+	}
+	return strings.ReplaceAll(strings.TrimSpace(config.BragPrompt), "%", "%%") + "\n\nBase it on the PR descriptions in %s."
+}
+
+// bragBullets returns the top-level bullets of the summarizer's output as "- " lines,
+// dropping anything around them such as an introduction. If there are none, the whole
+// output is kept.
+func bragBullets(text string) string {
+	var bullets []string
+	for _, line := range strings.Split(text, "\n") {
+		for _, marker := range []string{"- ", "* ", "+ "} {
+			if strings.HasPrefix(line, marker) {
+				bullets = append(bullets, "- "+strings.TrimSpace(strings.TrimPrefix(line, marker)))
+				break
+			}
+		}
+	}
+	if len(bullets) == 0 {
+		console.Warnf("The summarizer wrote the brag doc without a bulleted list; keeping it as written")
+		return strings.TrimSpace(text)
+	}
+	return strings.Join(bullets, "\n")
+}
+
+// writeBragDoc summarizes inputFile into a brag document of one-line accomplishments and
+// writes it to outputFile
+func writeBragDoc(ctx context.Context, svc *services, inputFile, outputFile string, config Config) error {
+	text, err := generateSummary(ctx, svc.summarizer, inputFile, bragPrompt(config), config)
+	if err != nil {
+		return withExitCode(exitSummarizer, fmt.Errorf("error generating brag doc: %w", err))
+	}
+
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing brag doc to %s", outputFile)
+	}
+
+	fmt.Fprintf(writer, "# Brag Doc\n\n%s\n", bragBullets(text))
+	return writer.Commit()
+}
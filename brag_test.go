@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBragPrompt(t *testing.T) {
+	assert.Equal(t, defaultBragPrompt, bragPrompt(Config{}))
+
+	// generateSummary fills in the reference to the input with Sprintf, which must leave
+	// a custom prompt as written
+	custom := bragPrompt(Config{BragPrompt: "  One bullet per 100% finished project.\n"})
+	assert.Equal(t, "One bullet per 100% finished project.\n\nBase it on the PR descriptions in @prs.md.", fmt.Sprintf(custom, "@prs.md"))
+}
+
+func TestBragBullets(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "plain list",
+			text: "- Shipped billing ([#1](https://github.com/o/r/pull/1))\n- Cut p99 latency in half",
+			want: "- Shipped billing ([#1](https://github.com/o/r/pull/1))\n- Cut p99 latency in half",
+		},
+		{
+			name: "introduction and other markers",
+			text: "Here are the accomplishments:\n\n* Shipped billing\n+ Cut latency  \n\nLet me know if you need more.",
+			want: "- Shipped billing\n- Cut latency",
+		},
+		{
+			name: "nested bullets are dropped",
+			text: "- Shipped billing\n  - invoices\n  - refunds\n- Cut latency",
+			want: "- Shipped billing\n- Cut latency",
+		},
+		{
+			name: "no list",
+			text: "\nThey shipped billing.\n",
+			want: "They shipped billing.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bragBullets(tt.text))
+		})
+	}
+}
+
+func TestWriteBragDoc(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "prs.md")
+	outputFile := filepath.Join(dir, "brag.md")
+	assert.NoError(t, os.WriteFile(inputFile, []byte("# Merged Pull Requests\n"), 0644))
+
+	var prompt string
+	svc := newServices(context.Background(), funcSummarizer(func(req SummaryRequest) (string, error) {
+		prompt = req.Prompt
+		return "Sure! Here is the list:\n\n- Shipped billing ([#1](https://github.com/o/r/pull/1))\n", nil
+	}), nil)
+	config := Config{Summarizer: summarizerEcho, ExtraPrompt: "Focus on reliability."}
+
+	assert.NoError(t, writeBragDoc(context.Background(), svc, inputFile, outputFile, config))
+	content, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Brag Doc\n\n- Shipped billing ([#1](https://github.com/o/r/pull/1))\n", string(content))
+	assert.Contains(t, prompt, "flat Markdown bulleted list")
+	assert.Contains(t, prompt, "the document below")
+	assert.Contains(t, prompt, "Focus on reliability.")
+}
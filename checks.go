@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// checkSummary counts the check runs on a PR's merge commit by outcome
+type checkSummary struct {
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	Other  int `json:"other"`
+}
+
+// String describes the summary for the metadata table, e.g. "✅ 12 passed, ❌ 1 failed"
+func (s checkSummary) String() string {
+	if s.Passed+s.Failed+s.Other == 0 {
+		return "No check runs"
+	}
+	var parts []string
+	if s.Passed > 0 {
+		parts = append(parts, fmt.Sprintf("✅ %d passed", s.Passed))
+	}
+	if s.Failed > 0 {
+		parts = append(parts, fmt.Sprintf("❌ %d failed", s.Failed))
+	}
+	if s.Other > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped, neutral, or unfinished", s.Other))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// annotateChecks sets Checks on each merged PR from the check runs on its merge commit.
+// It makes at least one API call per PR, so it is only done with include_checks. PRs
+// whose merge commit is unknown are left without a summary.
+func annotateChecks(ctx context.Context, client *github.Client, prs []PullRequestInfo) {
+	for i := range prs {
+		repo, err := parseNWO(prs[i].Repository)
+		if err != nil || prs[i].MergeCommitSHA == "" {
+			continue
+		}
+
+		summary, err := summarizeCheckRuns(ctx, client, repo, prs[i].MergeCommitSHA)
+		if err != nil {
+			console.Warnf("Failed to read checks of %s: %v", prs[i].URL, explainTokenAccessError(err, repo))
+			continue
+		}
+		prs[i].Checks = &summary
+	}
+}
+
+// summarizeCheckRuns pages through the check runs for a commit and counts them by outcome
+func summarizeCheckRuns(ctx context.Context, client *github.Client, repo NWO, sha string) (checkSummary, error) {
+	var summary checkSummary
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: perPageLimit}}
+
+	for {
+		result, resp, err := client.Checks.ListCheckRunsForRef(ctx, repo.Owner, repo.Name, sha, opts)
+		if err != nil {
+			return checkSummary{}, err
+		}
+
+		for _, run := range result.CheckRuns {
+			switch run.GetConclusion() {
+			case "success":
+				summary.Passed++
+			case "failure", "timed_out", "startup_failure":
+				summary.Failed++
+			default:
+				// neutral, skipped, cancelled, stale, action_required, or still running
+				summary.Other++
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotateChecks(t *testing.T) {
+	var client *github.Client
+	client = newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/commits/abc123/check-runs":
+			if r.URL.Query().Get("page") == "2" {
+				w.Write([]byte(`{"total_count": 4, "check_runs": [{"conclusion": "failure"}, {"conclusion": "skipped"}]}`))
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%srepos/owner/repo/commits/abc123/check-runs?page=2>; rel="next"`, client.BaseURL))
+			w.Write([]byte(`{"total_count": 4, "check_runs": [{"conclusion": "success"}, {"conclusion": "success"}]}`))
+		case "/repos/owner/repo/commits/def456/check-runs":
+			w.Write([]byte(`{"total_count": 0, "check_runs": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", URL: "https://github.com/owner/repo/pull/1", MergeCommitSHA: "abc123"},
+		{Repository: "owner/repo", URL: "https://github.com/owner/repo/pull/2", MergeCommitSHA: "def456"},
+		{Repository: "owner/repo", URL: "https://github.com/owner/repo/pull/3"},
+		{Repository: "owner/repo", URL: "https://github.com/owner/repo/pull/4", MergeCommitSHA: "gone"},
+	}
+	annotateChecks(context.Background(), client, prs)
+
+	assert.Equal(t, &checkSummary{Passed: 2, Failed: 1, Other: 1}, prs[0].Checks)
+	assert.Equal(t, &checkSummary{}, prs[1].Checks)
+	assert.Nil(t, prs[2].Checks, "merge commit unknown")
+	assert.Nil(t, prs[3].Checks, "checks could not be read")
+
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, IncludeChecks: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var buf strings.Builder
+	for _, pr := range prs {
+		writePRBlock(&buf, pr, 2, false, config)
+	}
+	output := buf.String()
+	assert.Contains(t, output, "| **Checks** | ✅ 2 passed, ❌ 1 failed, 1 skipped, neutral, or unfinished |\n")
+	assert.Contains(t, output, "| **Checks** | No check runs |\n")
+	assert.Contains(t, output, "| **Checks** | *Merge commit unknown* |\n")
+	assert.Contains(t, output, "| **Checks** | *Not available* |\n")
+}
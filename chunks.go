@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// File next to a summarizer input remembering the summary of each of its chunks
+	// between runs
+	chunkCacheFile = "chunk-summaries.json"
+
+	chunkPrompt = `The merged pull requests in %s are one part of a longer list, which is being summarized part by part.
+Summarize the contributions they describe so that the summaries of all parts can be combined into one. Keep the links to PRs, when the work was done, and its impact. Don't write any files.`
+)
+
+// chunkKey identifies a chunk's summary in the cache by a hash of the chunk and the
+// instructions sent with it, so that an edited chunk or prompt is summarized again
+func chunkKey(systemPrompt, chunk string) string {
+	sum := sha256.Sum256([]byte(systemPrompt + "\x00" + chunkPrompt + "\x00" + chunk))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadChunkCache reads the cached chunk summaries, returning an empty cache if there is none
+func loadChunkCache(path string) (map[string]string, error) {
+	cache := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk cache %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// writeChunkCache saves the chunk summaries for the next run
+func writeChunkCache(cache map[string]string, path string) error {
+	writer, err := getOutputWriter(path)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cache); err != nil {
+		return fmt.Errorf("failed to encode chunk cache: %w", err)
+	}
+	return writer.Commit()
+}
+
+// splitIntoChunks splits Markdown into chunks of at most limit characters, breaking only
+// before a heading outside a code block so that PRs aren't cut in half. A section longer
+// than limit becomes a chunk of its own.
+func splitIntoChunks(text string, limit int) []string {
+	// Each section runs from one heading to the next
+	var sections []string
+	var section strings.Builder
+	inFence := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "#") && section.Len() > 0 {
+			sections = append(sections, section.String())
+			section.Reset()
+		}
+		section.WriteString(line)
+	}
+	if section.Len() > 0 {
+		sections = append(sections, section.String())
+	}
+
+	var chunks []string
+	var chunk strings.Builder
+	for _, section := range sections {
+		if chunk.Len() > 0 && chunk.Len()+len(section) > limit {
+			chunks = append(chunks, chunk.String())
+			chunk.Reset()
+		}
+		chunk.WriteString(section)
+	}
+	if chunk.Len() > 0 {
+		chunks = append(chunks, chunk.String())
+	}
+	return chunks
+}
+
+// summarizeChunks summarizes inputFile part by part when it is longer than
+// summary_chunk_chars, and returns a temporary file next to it holding the summaries of
+// the parts in order, for the final summary to be made from. It returns "" when the input
+// fits in one part. Each part's summary is cached as soon as it is made, so that a rerun
+// after a failure only sends the parts that weren't summarized yet.
+func summarizeChunks(ctx context.Context, summarizer Summarizer, inputFile string, config Config) (string, error) {
+	content, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", inputFile, err)
+	}
+	chunks := splitIntoChunks(string(content), config.SummaryChunkChars)
+	if len(chunks) < 2 {
+		return "", nil
+	}
+
+	dir := filepath.Dir(inputFile)
+	cachePath := filepath.Join(dir, chunkCacheFile)
+	cache, err := loadChunkCache(cachePath)
+	if err != nil {
+		return "", err
+	}
+
+	systemPrompt := strings.TrimSpace(config.SystemPrompt)
+	summaries := make([]string, len(chunks))
+	cached := 0
+	for i, chunk := range chunks {
+		if summary, ok := cache[chunkKey(systemPrompt, chunk)]; ok && !config.RefreshChunks {
+			summaries[i] = summary
+			cached++
+		}
+	}
+	console.Infof("Summarizing %s in %d chunks (%d cached)...", inputFile, len(chunks), cached)
+
+	for i, chunk := range chunks {
+		if summaries[i] != "" {
+			continue
+		}
+		summary, err := summarizeChunk(ctx, summarizer, dir, chunk, systemPrompt, config)
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+		summaries[i] = summary
+		cache[chunkKey(systemPrompt, chunk)] = summary
+		if err := writeChunkCache(cache, cachePath); err != nil {
+			return "", err
+		}
+	}
+
+	combined, err := os.CreateTemp(dir, "chunk-summaries-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create chunk summaries file: %w", err)
+	}
+	fmt.Fprintf(combined, "# Summaries of Merged Pull Requests, Part by Part\n\n")
+	for i, summary := range summaries {
+		fmt.Fprintf(combined, "## Part %d\n\n%s\n\n", i+1, strings.TrimSpace(summary))
+	}
+	if err := combined.Close(); err != nil {
+		os.Remove(combined.Name())
+		return "", fmt.Errorf("failed to write chunk summaries file: %w", err)
+	}
+	return combined.Name(), nil
+}
+
+// summarizeChunk asks the summarizer for the summary of one chunk, which it reads from a
+// temporary file in dir
+func summarizeChunk(ctx context.Context, summarizer Summarizer, dir, chunk, systemPrompt string, config Config) (string, error) {
+	inputFile, err := os.CreateTemp(dir, "chunk-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create chunk input: %w", err)
+	}
+	defer os.Remove(inputFile.Name())
+	if _, err := inputFile.WriteString(chunk); err != nil {
+		inputFile.Close()
+		return "", fmt.Errorf("failed to write chunk input: %w", err)
+	}
+	if err := inputFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write chunk input: %w", err)
+	}
+
+	return summarizer.Summarize(ctx, SummaryRequest{
+		SystemPrompt: systemPrompt,
+		Prompt:       fmt.Sprintf(chunkPrompt, inputReference(config.Summarizer, inputFile.Name())),
+		InputFile:    inputFile.Name(),
+	})
+}
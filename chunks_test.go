@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitIntoChunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "fits in one chunk",
+			text:  "# PRs\n\n## One\n\nFirst\n",
+			limit: 100,
+			want:  []string{"# PRs\n\n## One\n\nFirst\n"},
+		},
+		{
+			name:  "breaks before headings",
+			text:  "## One\n\nFirst\n\n## Two\n\nSecond\n\n## Three\n\nThird\n",
+			limit: 31,
+			want:  []string{"## One\n\nFirst\n\n## Two\n\nSecond\n\n", "## Three\n\nThird\n"},
+		},
+		{
+			name:  "long section stays whole",
+			text:  "## One\n\nA rather long description\n\n## Two\n\nSecond\n",
+			limit: 10,
+			want:  []string{"## One\n\nA rather long description\n\n", "## Two\n\nSecond\n"},
+		},
+		{
+			name:  "no break inside a code block",
+			text:  "## One\n\n```\n# not a heading\n```\n",
+			limit: 10,
+			want:  []string{"## One\n\n```\n# not a heading\n```\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitIntoChunks(tt.text, tt.limit))
+		})
+	}
+}
+
+func TestChunkedSummary(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "prs.md")
+	if err := os.WriteFile(inputFile, []byte("## One\n\nFirst\n\n## Two\n\nSecond\n\n## Three\n\nThird\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := Config{Summarizer: summarizerChat, SummaryChunkChars: 20}
+
+	// Summarizes each chunk as its first line, failing on chunks in failOn, and records
+	// the chunks it was asked about and the input of the final summary
+	var chunks []string
+	var final string
+	summarizer := func(failOn string) funcSummarizer {
+		return func(req SummaryRequest) (string, error) {
+			content, err := os.ReadFile(req.InputFile)
+			if err != nil {
+				return "", err
+			}
+			if !strings.Contains(req.Prompt, "one part of a longer list") {
+				final = string(content)
+				return "the summary", nil
+			}
+			chunks = append(chunks, string(content))
+			if failOn != "" && strings.Contains(string(content), failOn) {
+				return "", errors.New("summarizer failed")
+			}
+			return "summary of " + strings.SplitN(string(content), "\n", 2)[0], nil
+		}
+	}
+
+	_, err := generateSummary(context.Background(), summarizer("Two"), inputFile, defaultPrompt, config)
+	assert.ErrorContains(t, err, "failed to summarize chunk 2 of 3: summarizer failed")
+	assert.Len(t, chunks, 2)
+
+	// The rerun resumes with the chunk that failed
+	chunks = nil
+	summary, err := generateSummary(context.Background(), summarizer(""), inputFile, defaultPrompt, config)
+	assert.NoError(t, err)
+	assert.Equal(t, "the summary", summary)
+	assert.Equal(t, []string{"## Two\n\nSecond\n\n", "## Three\n\nThird\n"}, chunks)
+	assert.Contains(t, final, "## Part 1\n\nsummary of ## One\n\n## Part 2\n\nsummary of ## Two\n\n## Part 3\n\nsummary of ## Three")
+
+	// Nothing is summarized again, unless asked to
+	chunks = nil
+	_, err = generateSummary(context.Background(), summarizer(""), inputFile, defaultPrompt, config)
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	config.RefreshChunks = true
+	_, err = generateSummary(context.Background(), summarizer(""), inputFile, defaultPrompt, config)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 3)
+
+	// Only prs.md and the cache are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{chunkCacheFile, "prs.md"}, names)
+}
+
+func TestChunkedSummaryShortInput(t *testing.T) {
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "prs.md")
+	if err := os.WriteFile(inputFile, []byte("## One\n\nFirst\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs []string
+	summarizer := funcSummarizer(func(req SummaryRequest) (string, error) {
+		inputs = append(inputs, req.InputFile)
+		return "the summary", nil
+	})
+	_, err := generateSummary(context.Background(), summarizer, inputFile, defaultPrompt, Config{SummaryChunkChars: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{inputFile}, inputs, "a short input is summarized directly")
+	assert.NoFileExists(t, filepath.Join(dir, chunkCacheFile))
+}
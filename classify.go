@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// File in the output directory remembering each PR's impact tag between runs
+	classificationCacheFile = "classifications.json"
+
+	classifyPrompt = `Classify each pull request in %s by its impact. Choose exactly one tag for each pull request from: %s.
+Reply with one line per pull request in the form "<number>: <tag>", using the pull request numbers from the input, and nothing else. Don't write any files.`
+)
+
+// defaultImpactTags are the impact tags used when impact_tags is not set
+var defaultImpactTags = []string{"feature", "fix", "refactor", "perf", "docs"}
+
+// classificationLinePattern matches a "<number>: <tag>" line of a classification reply
+var classificationLinePattern = regexp.MustCompile(`^\s*(\d+)\s*[:.)-]\s*` + "`?" + `([A-Za-z-]+)`)
+
+// classificationKey identifies a PR's classification in the cache. It includes a hash of
+// the description so that an edited PR is classified again.
+func classificationKey(pr PullRequestInfo) string {
+	sum := sha256.Sum256([]byte(pr.Description))
+	return pr.URL + "@" + hex.EncodeToString(sum[:8])
+}
+
+// loadClassificationCache reads the cached impact tags, returning an empty cache if there is none
+func loadClassificationCache(path string) (map[string]string, error) {
+	cache := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classification cache %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse classification cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// writeClassificationCache saves the impact tags for the next run
+func writeClassificationCache(cache map[string]string, path string) error {
+	writer, err := getOutputWriter(path)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cache); err != nil {
+		return fmt.Errorf("failed to encode classification cache: %w", err)
+	}
+	return writer.Commit()
+}
+
+// classifyPRs sets ImpactTag on each PR, asking the summarizer only about PRs that are not
+// already in the cache in the output directory
+func classifyPRs(ctx context.Context, summarizer Summarizer, prs []PullRequestInfo, config Config) error {
+	cachePath := filepath.Join(config.OutputDir, classificationCacheFile)
+	cache, err := loadClassificationCache(cachePath)
+	if err != nil {
+		return err
+	}
+
+	var pending []int
+	for i := range prs {
+		if tag, ok := cache[classificationKey(prs[i])]; ok {
+			prs[i].ImpactTag = tag
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	console.Infof("Classifying %d PRs (%d cached)...", len(pending), len(prs)-len(pending))
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// The summarizer reads its input from a file, so write the pending PRs to one
+	inputFile, err := os.CreateTemp(config.OutputDir, "classify-*.md")
+	if err != nil {
+		return fmt.Errorf("failed to create classification input: %w", err)
+	}
+	defer os.Remove(inputFile.Name())
+	for n, i := range pending {
+		description, _ := extractDescription(prs[i], config)
+		fmt.Fprintf(inputFile, "## Pull request %d\n\nTitle: %s\nRepository: %s\n\n%s\n\n", n+1, prs[i].Title, prs[i].Repository, description)
+	}
+	if err := inputFile.Close(); err != nil {
+		return fmt.Errorf("failed to write classification input: %w", err)
+	}
+
+	reply, err := summarizer.Summarize(ctx, SummaryRequest{
+		Prompt:    fmt.Sprintf(classifyPrompt, inputReference(config.Summarizer, inputFile.Name()), strings.Join(config.ImpactTags, ", ")),
+		InputFile: inputFile.Name(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to classify PRs: %w", err)
+	}
+
+	tags := parseClassifications(reply, len(pending), config.ImpactTags)
+	for n, i := range pending {
+		if tag, ok := tags[n+1]; ok {
+			prs[i].ImpactTag = tag
+			cache[classificationKey(prs[i])] = tag
+		}
+	}
+	if len(tags) < len(pending) {
+		console.Warnf("The summarizer did not give a valid impact tag for %d of %d PRs", len(pending)-len(tags), len(pending))
+	}
+
+	return writeClassificationCache(cache, cachePath)
+}
+
+// parseClassifications reads "<number>: <tag>" lines from a classification reply, keeping
+// only numbers from 1 to count and tags from the allowed set
+func parseClassifications(reply string, count int, allowed []string) map[int]string {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, tag := range allowed {
+		allowedSet[tag] = true
+	}
+
+	tags := make(map[int]string)
+	for _, line := range strings.Split(reply, "\n") {
+		match := classificationLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		n, err := strconv.Atoi(match[1])
+		tag := strings.ToLower(match[2])
+		if err != nil || n < 1 || n > count || !allowedSet[tag] {
+			continue
+		}
+		tags[n] = tag
+	}
+	return tags
+}
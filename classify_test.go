@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClassifications(t *testing.T) {
+	reply := "1: feature\n2. FIX\n3) `perf`\n4: rewrite\n9: docs\nHere you go!\n  5 - docs"
+	assert.Equal(t, map[int]string{1: "feature", 2: "fix", 3: "perf", 5: "docs"}, parseClassifications(reply, 5, defaultImpactTags))
+}
+
+func TestClassifyPRs(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: t.TempDir(), Repos: []string{"owner/repo"}, ClassifyPRs: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Answers classification requests by tagging every PR in the input "fix"
+	var inputs []string
+	classifier := funcSummarizer(func(req SummaryRequest) (string, error) {
+		content, err := os.ReadFile(req.InputFile)
+		if err != nil {
+			return "", err
+		}
+		inputs = append(inputs, string(content))
+
+		var lines []string
+		for n := 1; n <= strings.Count(string(content), "## Pull request "); n++ {
+			lines = append(lines, fmt.Sprintf("%d: fix", n))
+		}
+		return strings.Join(lines, "\n"), nil
+	})
+	newPRs := func() []PullRequestInfo {
+		return []PullRequestInfo{
+			{Repository: "owner/repo", Title: "One", Description: "First", URL: "https://github.com/owner/repo/pull/1"},
+			{Repository: "owner/repo", Title: "Two", Description: "Second", URL: "https://github.com/owner/repo/pull/2"},
+		}
+	}
+
+	prs := newPRs()
+	assert.NoError(t, classifyPRs(context.Background(), classifier, prs, config))
+	assert.Equal(t, "fix", prs[0].ImpactTag)
+	assert.Equal(t, "fix", prs[1].ImpactTag)
+	assert.Len(t, inputs, 1, "PRs are classified in one batch")
+	assert.Contains(t, inputs[0], "Title: Two")
+
+	// A rerun uses the cache
+	prs = newPRs()
+	assert.NoError(t, classifyPRs(context.Background(), classifier, prs, config))
+	assert.Equal(t, "fix", prs[1].ImpactTag)
+	assert.Len(t, inputs, 1)
+
+	// Editing a description invalidates only that PR
+	prs = newPRs()
+	prs[1].Description = "Second, edited"
+	assert.NoError(t, classifyPRs(context.Background(), classifier, prs, config))
+	assert.Len(t, inputs, 2)
+	assert.NotContains(t, inputs[1], "Title: One")
+	assert.Contains(t, inputs[1], "Title: Two")
+
+	entries, err := os.ReadDir(config.OutputDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "only the cache is left behind")
+}
+
+func TestImpactTagConfig(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, ImpactTags: []string{" Feature", "security"}}
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{"feature", "security"}, config.ImpactTags)
+
+	config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, ImpactTags: []string{"fix", "FIX"}}
+	assert.ErrorContains(t, config.Parse(), "duplicate impact tag 'fix'")
+
+	config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, ImpactTags: []string{"big fix"}}
+	assert.ErrorContains(t, config.Parse(), "tags must be single words")
+
+	config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+	assert.NoError(t, config.Parse())
+	config.ImpactTags[0] = "changed"
+	assert.Equal(t, "feature", defaultImpactTags[0], "the defaults are copied, not shared")
+}
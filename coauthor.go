@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// coAuthorTrailerPattern matches "Co-authored-by: Name <email>" commit trailers
+var coAuthorTrailerPattern = regexp.MustCompile(`(?im)^[ \t]*co-authored-by:[ \t]*(.*?)[ \t]*<([^>\s]+)>[ \t]*$`)
+
+// coAuthor is a person credited in a Co-authored-by trailer
+type coAuthor struct {
+	Name  string
+	Email string
+}
+
+// parseCoAuthorTrailers extracts the Co-authored-by trailers from a commit message
+func parseCoAuthorTrailers(message string) []coAuthor {
+	var authors []coAuthor
+	for _, match := range coAuthorTrailerPattern.FindAllStringSubmatch(message, -1) {
+		authors = append(authors, coAuthor{
+			Name:  strings.TrimSpace(match[1]),
+			Email: strings.TrimSpace(match[2]),
+		})
+	}
+	return authors
+}
+
+// matchesIdentity reports whether a co-author is the given GitHub user, either by one of
+// their configured emails, their GitHub noreply email, or a name equal to their login.
+// When domains is non-empty, only the configured emails and emails under one of the
+// domains can match, so that a stranger with the same name isn't mistaken for the user.
+func matchesIdentity(author coAuthor, login string, emails, domains []string) bool {
+	email := strings.ToLower(author.Email)
+	for _, candidate := range emails {
+		if email == strings.ToLower(strings.TrimSpace(candidate)) {
+			return true
+		}
+	}
+
+	if len(domains) > 0 && !emailInDomains(email, domains) {
+		return false
+	}
+
+	login = strings.ToLower(login)
+	if login == "" {
+		return false
+	}
+
+	// GitHub noreply addresses look like "login@users.noreply.github.com" or
+	// "12345+login@users.noreply.github.com"
+	if local, found := strings.CutSuffix(email, "@users.noreply.github.com"); found {
+		if _, name, hasID := strings.Cut(local, "+"); hasID {
+			local = name
+		}
+		if local == login {
+			return true
+		}
+	}
+
+	return strings.ToLower(author.Name) == login
+}
+
+// emailInDomains reports whether email is at one of the domains or a subdomain of one,
+// so "mycorp.com" covers "jane@eng.mycorp.com" but not "jane@notmycorp.com". The domains
+// must already be lowercase.
+func emailInDomains(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	host := strings.ToLower(email[at+1:])
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCoAuthorSearchQuery creates a search query for merged PRs in the window that
+// were opened by someone other than the configured user
+func buildCoAuthorSearchQuery(repo NWO, config Config) *searchQuery {
+	query := newSearchQuery(
+		qualifier(fmt.Sprintf("repo:%s/%s", repo.Owner, repo.Name)),
+		qualifier("is:pr"),
+		qualifier("is:merged"),
+		qualifier("-author:"+config.Username),
+		windowQualifier(config),
+	)
+
+	console.Infof("GitHub co-author search query for %s/%s: %s", repo.Owner, repo.Name, query)
+	return query
+}
+
+// getCoAuthoredPRs finds merged PRs opened by others in which one of the commits credits
+// the configured user in a Co-authored-by trailer. This lists the commits of every
+// merged PR in the window, so it is considerably more expensive than the author search.
+// Like getMergedPRsWithProgress, it returns what it found so far on error.
+func getCoAuthoredPRs(ctx context.Context, svc *services, repo NWO, config Config) ([]PullRequestInfo, error) {
+	var coAuthored []PullRequestInfo
+
+	client, err := svc.githubClient()
+	if err != nil {
+		return nil, err
+	}
+
+	query := buildCoAuthorSearchQuery(repo, config)
+
+	opts := &github.SearchOptions{
+		Sort:  "created",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: perPageLimit,
+		},
+	}
+
+	for {
+		result, resp, err := searchIssuesDegrading(ctx, client, query, opts)
+		if err != nil {
+			return coAuthored, fmt.Errorf("failed to search PRs (page %d): %w", max(opts.Page, 1), explainTokenAccessError(err, repo))
+		}
+		dumpSearchResults(svc.debugSearch, query.String(), opts.Page, result)
+
+		for _, issue := range query.filter(result.Issues) {
+			found, err := prHasCoAuthor(ctx, client, repo, issue.GetNumber(), config)
+			if err != nil {
+				console.Warnf("Failed to list commits for #%d: %v", issue.GetNumber(), err)
+				continue
+			}
+			if !found {
+				continue
+			}
+
+			prInfo := getPRInfo(ctx, client, svc.prCache, repo, issue, config)
+			prInfo.CoAuthored = true
+			coAuthored = append(coAuthored, prInfo)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	console.Infof("Found %d co-authored PRs in %s/%s", len(coAuthored), repo.Owner, repo.Name)
+	return coAuthored, nil
+}
+
+// prHasCoAuthor reports whether any commit of the PR credits the configured user as a co-author
+func prHasCoAuthor(ctx context.Context, client *github.Client, repo NWO, number int, config Config) (bool, error) {
+	opts := &github.ListOptions{PerPage: perPageLimit}
+
+	for {
+		commits, resp, err := client.PullRequests.ListCommits(ctx, repo.Owner, repo.Name, number, opts)
+		if err != nil {
+			return false, err
+		}
+
+		for _, commit := range commits {
+			for _, author := range parseCoAuthorTrailers(commit.GetCommit().GetMessage()) {
+				if matchesIdentity(author, config.Username, config.CoAuthorEmails, config.IdentityDomains) {
+					return true, nil
+				}
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// mergePRsByURL appends the PRs in extra that are not already in prs
+func mergePRsByURL(prs, extra []PullRequestInfo) []PullRequestInfo {
+	seen := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		seen[pr.URL] = true
+	}
+	for _, pr := range extra {
+		if !seen[pr.URL] {
+			seen[pr.URL] = true
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCoAuthorTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected []coAuthor
+	}{
+		{
+			name:     "no trailers",
+			message:  "Fix the thing\n\nLonger explanation.",
+			expected: nil,
+		},
+		{
+			name: "multiple trailers with varied casing",
+			message: `Pair on the parser
+
+Co-authored-by: Jane Doe <jane@example.com>
+co-authored-by:  octocat <12345+octocat@users.noreply.github.com>
+Signed-off-by: Someone <someone@example.com>`,
+			expected: []coAuthor{
+				{Name: "Jane Doe", Email: "jane@example.com"},
+				{Name: "octocat", Email: "12345+octocat@users.noreply.github.com"},
+			},
+		},
+		{
+			name:     "mention in body is not a trailer",
+			message:  "Thanks to the Co-authored-by: Jane <jane@example.com> convention",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseCoAuthorTrailers(tt.message))
+		})
+	}
+}
+
+func TestMatchesIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		author   coAuthor
+		login    string
+		emails   []string
+		domains  []string
+		expected bool
+	}{
+		{
+			name:     "configured email, case-insensitive",
+			author:   coAuthor{Name: "Jane Doe", Email: "Jane@Example.com"},
+			login:    "jdoe",
+			emails:   []string{"jane@example.com"},
+			expected: true,
+		},
+		{
+			name:     "noreply email with ID",
+			author:   coAuthor{Name: "Jane Doe", Email: "12345+JDoe@users.noreply.github.com"},
+			login:    "jdoe",
+			expected: true,
+		},
+		{
+			name:     "noreply email without ID",
+			author:   coAuthor{Name: "Jane Doe", Email: "jdoe@users.noreply.github.com"},
+			login:    "jdoe",
+			expected: true,
+		},
+		{
+			name:     "name equal to login",
+			author:   coAuthor{Name: "jdoe", Email: "jane@elsewhere.com"},
+			login:    "jdoe",
+			expected: true,
+		},
+		{
+			name:     "someone else's noreply email",
+			author:   coAuthor{Name: "Other", Email: "999+other@users.noreply.github.com"},
+			login:    "jdoe",
+			expected: false,
+		},
+		{
+			name:     "unrelated email",
+			author:   coAuthor{Name: "Jane Doe", Email: "jane@example.com"},
+			login:    "jdoe",
+			expected: false,
+		},
+		{
+			name:     "name match in an allowed domain",
+			author:   coAuthor{Name: "jdoe", Email: "jane@mycorp.com"},
+			login:    "jdoe",
+			domains:  []string{"mycorp.com"},
+			expected: true,
+		},
+		{
+			name:     "name match outside the allowed domains",
+			author:   coAuthor{Name: "jdoe", Email: "jdoe@elsewhere.com"},
+			login:    "jdoe",
+			domains:  []string{"mycorp.com"},
+			expected: false,
+		},
+		{
+			name:     "noreply email outside the allowed domains",
+			author:   coAuthor{Name: "Jane Doe", Email: "12345+jdoe@users.noreply.github.com"},
+			login:    "jdoe",
+			domains:  []string{"mycorp.com"},
+			expected: false,
+		},
+		{
+			name:     "configured email outside the allowed domains",
+			author:   coAuthor{Name: "Jane Doe", Email: "jane@example.com"},
+			login:    "jdoe",
+			emails:   []string{"jane@example.com"},
+			domains:  []string{"mycorp.com"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesIdentity(tt.author, tt.login, tt.emails, tt.domains))
+		})
+	}
+}
+
+func TestEmailInDomains(t *testing.T) {
+	domains := []string{"mycorp.com", "partner.io"}
+
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{email: "jane@mycorp.com", expected: true},
+		{email: "Jane@MyCorp.COM", expected: true},
+		{email: "jane@eng.mycorp.com", expected: true},
+		{email: "jane@a.b.partner.io", expected: true},
+		{email: "jane@notmycorp.com", expected: false},
+		{email: "jane@mycorp.com.evil.net", expected: false},
+		{email: "mycorp.com", expected: false},
+		{email: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			assert.Equal(t, tt.expected, emailInDomains(tt.email, domains))
+		})
+	}
+}
+
+func TestParseIdentityDomains(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, IdentityDomains: []string{" @MyCorp.com", "partner.io"}}
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{"mycorp.com", "partner.io"}, config.IdentityDomains)
+
+	config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, IdentityDomains: []string{"jane@mycorp.com"}}
+	assert.ErrorContains(t, config.Parse(), "invalid identity domain 'jane@mycorp.com'")
+}
+
+func TestMergePRsByURL(t *testing.T) {
+	authored := []PullRequestInfo{{Title: "mine", URL: "1"}}
+	coAuthored := []PullRequestInfo{{Title: "dup", URL: "1"}, {Title: "paired", URL: "2"}, {Title: "paired again", URL: "2"}}
+
+	merged := mergePRsByURL(authored, coAuthored)
+	assert.Equal(t, []string{"mine", "paired"}, titles(merged))
+}
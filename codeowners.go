@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// codeownersLocations are where GitHub looks for a repository's CODEOWNERS file, in the
+// order it looks
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule is one line of a CODEOWNERS file: a path pattern and who owns it
+type codeownersRule struct {
+	Pattern string
+	Owners  []string
+	match   *regexp.Regexp
+}
+
+// parseCodeowners parses the rules in a CODEOWNERS file, in file order. Comments, blank
+// lines, and patterns that can't be converted are skipped.
+func parseCodeowners(content string) []codeownersRule {
+	var rules []codeownersRule
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		match, err := codeownersPatternRegexp(fields[0])
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeownersRule{Pattern: fields[0], Owners: fields[1:], match: match})
+	}
+	return rules
+}
+
+// codeownersPatternRegexp converts a CODEOWNERS pattern, which follows gitignore rules,
+// into a regular expression over repository paths. A pattern matches a file or anything
+// under a matching directory; it is anchored to the repository root if it starts with or
+// contains a "/", and otherwise matches at any depth.
+func codeownersPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			expr.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	if dirOnly {
+		expr.WriteString("/.*$")
+	} else {
+		expr.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(expr.String())
+}
+
+// ownersOf returns the owners of path, from the last rule matching it as GitHub does
+func ownersOf(rules []codeownersRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].match.MatchString(path) {
+			return rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// ownedBy reports whether any of owners (e.g. "@alice", "@myorg/payments") owns path
+func ownedBy(rules []codeownersRule, path string, owners []string) bool {
+	for _, owner := range ownersOf(rules, path) {
+		for _, candidate := range owners {
+			if strings.EqualFold(owner, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codeownersOwners returns the CODEOWNERS owners whose areas count as the user's: the
+// user themself and each of codeowners_teams
+func codeownersOwners(config Config) []string {
+	owners := []string{"@" + config.Username}
+	for _, team := range config.CodeownersTeams {
+		owners = append(owners, "@"+strings.TrimPrefix(team, "@"))
+	}
+	return owners
+}
+
+// codeownersCache fetches each repository's CODEOWNERS rules, remembering them for the
+// rest of the run so that they are fetched once however many users are reported on
+type codeownersCache struct {
+	getClient func() (*github.Client, error)
+	rules     map[NWO][]codeownersRule
+}
+
+// newCodeownersCache creates an empty CODEOWNERS cache that uses getClient for lookups
+func newCodeownersCache(getClient func() (*github.Client, error)) *codeownersCache {
+	return &codeownersCache{getClient: getClient, rules: make(map[NWO][]codeownersRule)}
+}
+
+// Get returns the CODEOWNERS rules of repo. A repository without a CODEOWNERS file, or
+// whose file can't be read, has no rules, and so no paths owned by anyone.
+func (c *codeownersCache) Get(ctx context.Context, repo NWO) []codeownersRule {
+	if rules, ok := c.rules[repo]; ok {
+		return rules
+	}
+
+	rules, err := c.fetch(ctx, repo)
+	if err != nil {
+		console.Warnf("Failed to read CODEOWNERS of %s/%s, so none of its PRs are in owned areas: %v", repo.Owner, repo.Name, err)
+	}
+	c.rules[repo] = rules
+	return rules
+}
+
+// fetch reads the first CODEOWNERS file found in repo's default branch
+func (c *codeownersCache) fetch(ctx context.Context, repo NWO) ([]codeownersRule, error) {
+	client, err := c.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range codeownersLocations {
+		file, _, resp, err := client.Repositories.GetContents(ctx, repo.Owner, repo.Name, location, nil)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, explainTokenAccessError(err, repo)
+		}
+		if file == nil {
+			// A directory by that name
+			continue
+		}
+
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", location, err)
+		}
+		return parseCodeowners(content), nil
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file in %s", strings.Join(codeownersLocations, ", "))
+}
+
+// listPRFiles returns the paths a PR changes, including the old paths of renamed files
+func listPRFiles(ctx context.Context, client *github.Client, repo NWO, number int) ([]string, error) {
+	var paths []string
+	opts := &github.ListOptions{PerPage: perPageLimit}
+	for {
+		files, resp, err := client.PullRequests.ListFiles(ctx, repo.Owner, repo.Name, number, opts)
+		if err != nil {
+			return paths, err
+		}
+		for _, file := range files {
+			paths = append(paths, file.GetFilename())
+			if file.GetPreviousFilename() != "" {
+				paths = append(paths, file.GetPreviousFilename())
+			}
+		}
+		if resp.NextPage == 0 {
+			return paths, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// codeownersFilter keeps only PRs that change at least one path owned by one of owners
+// according to their repository's CODEOWNERS. Listing a PR's files costs at least one API
+// call per PR, so this filter runs after the others. PRs whose files can't be listed are
+// kept, since dropping them would silently hide work.
+func codeownersFilter(ctx context.Context, client *github.Client, cache *codeownersCache, owners []string) prFilter {
+	ownerList := strings.Join(owners, " or ")
+	return prFilter{
+		Name: "codeowners",
+		Keep: func(pr PullRequestInfo) (bool, string) {
+			repo, err := parseNWO(pr.Repository)
+			if err != nil || pr.Number == 0 {
+				return true, "changed files unknown"
+			}
+
+			paths, err := listPRFiles(ctx, client, repo, pr.Number)
+			if err != nil {
+				console.Warnf("Failed to list files of %s, keeping it: %v", pr.URL, explainTokenAccessError(err, repo))
+				return true, "changed files could not be listed"
+			}
+
+			rules := cache.Get(ctx, repo)
+			var owned []string
+			for _, path := range paths {
+				if ownedBy(rules, path, owners) {
+					owned = append(owned, path)
+				}
+			}
+			if len(owned) == 0 {
+				return false, fmt.Sprintf("none of %d changed files are owned by %s", len(paths), ownerList)
+			}
+			return true, fmt.Sprintf("%d of %d changed files are owned by %s, e.g. %s", len(owned), len(paths), ownerList, owned[0])
+		},
+	}
+}
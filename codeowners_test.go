@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeownersPatternRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "any/file.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.jsx", false},
+		{"/docs/", "docs/guide/intro.md", true},
+		{"/docs/", "web/docs/intro.md", false},
+		{"docs/", "web/docs/intro.md", true},
+		{"apps/", "apps/api/main.go", true},
+		{"/build/logs", "build/logs/today.log", true},
+		{"/build/logs", "build/logsfile", false},
+		{"README.md", "services/api/README.md", true},
+		{"/README.md", "services/api/README.md", false},
+		{"docs/*", "docs/intro.md", true},
+		{"docs/*", "docs/guide/intro.md", true},
+		{"**/payments", "services/payments/charge.go", true},
+		{"/services/**/handlers", "services/api/v2/handlers/user.go", true},
+		{"/services/**/handlers", "services/handlers/user.go", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"a.b", "axb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s", tt.pattern, tt.path), func(t *testing.T) {
+			match, err := codeownersPatternRegexp(tt.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, match.MatchString(tt.path))
+		})
+	}
+}
+
+func TestOwnedBy(t *testing.T) {
+	rules := parseCodeowners(`# Default owners
+*                 @myorg/platform
+
+/services/payments/  @myorg/payments @alice  # payments team
+/services/payments/vendor/
+*.md              @docs-bot
+`)
+	assert.Len(t, rules, 4)
+
+	owners := []string{"@alice", "@myorg/payments"}
+	assert.True(t, ownedBy(rules, "services/payments/charge.go", owners))
+	assert.True(t, ownedBy(rules, "services/payments/charge.go", []string{"@ALICE"}), "owners are case-insensitive")
+	assert.False(t, ownedBy(rules, "services/search/index.go", owners), "owned by the default team")
+	assert.False(t, ownedBy(rules, "services/payments/vendor/lib.go", owners), "a later rule without owners takes precedence")
+	assert.False(t, ownedBy(rules, "services/payments/README.md", owners), "the last matching rule wins")
+	assert.False(t, ownedBy(nil, "services/payments/charge.go", owners), "no CODEOWNERS file")
+}
+
+func TestCodeownersFilter(t *testing.T) {
+	codeowners := base64.StdEncoding.EncodeToString([]byte("/billing/ @alice\n/search/ @myorg/search\n"))
+	contentRequests := 0
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/contents/.github/CODEOWNERS":
+			contentRequests++
+			fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": %q}`, codeowners)
+		case "/repos/owner/repo/pulls/1/files":
+			w.Write([]byte(`[{"filename": "billing/invoice.go"}, {"filename": "README.md"}]`))
+		case "/repos/owner/repo/pulls/2/files":
+			w.Write([]byte(`[{"filename": "web/app.js"}]`))
+		case "/repos/owner/repo/pulls/3/files":
+			w.Write([]byte(`[{"filename": "search/new.go", "previous_filename": "web/old.go"}]`))
+		case "/repos/owner/empty/pulls/1/files":
+			w.Write([]byte(`[{"filename": "billing/invoice.go"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	config := Config{
+		Username: "alice", OutputDir: "out", Repos: []string{"owner/repo"},
+		CodeownersFilter: true, CodeownersTeams: []string{"myorg/search"},
+	}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	owners := codeownersOwners(config)
+	assert.Equal(t, []string{"@alice", "@myorg/search"}, owners)
+
+	cache := newCodeownersCache(func() (*github.Client, error) { return client, nil })
+	filter := codeownersFilter(context.Background(), client, cache, owners)
+
+	tests := []struct {
+		name string
+		pr   PullRequestInfo
+		want bool
+	}{
+		{"touches an owned path", PullRequestInfo{Repository: "owner/repo", Number: 1}, true},
+		{"touches only other paths", PullRequestInfo{Repository: "owner/repo", Number: 2}, false},
+		{"owned by a team", PullRequestInfo{Repository: "owner/repo", Number: 3}, true},
+		{"files can't be listed", PullRequestInfo{Repository: "owner/repo", Number: 4}, true},
+		{"repository without CODEOWNERS", PullRequestInfo{Repository: "owner/empty", Number: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, reason := filter.Keep(tt.pr)
+			assert.Equal(t, tt.want, keep, reason)
+		})
+	}
+	assert.Equal(t, 1, contentRequests, "CODEOWNERS is fetched once per repository")
+}
+
+func TestCodeownersConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  bool
+		teams   []string
+		wantErr bool
+	}{
+		{"filter alone", true, nil, false},
+		{"teams with and without @", true, []string{"myorg/search", "@myorg/payments"}, false},
+		{"teams without the filter", false, []string{"myorg/search"}, true},
+		{"team without an org", true, []string{"search"}, true},
+		{"team with an empty name", true, []string{"myorg/"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Username: "alice", OutputDir: "out", Repos: []string{"owner/repo"}, CodeownersFilter: tt.filter, CodeownersTeams: tt.teams}
+			err := config.Parse()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// openDebugSearchOutput opens where -debug-search writes raw search results: stderr for
+// "-", otherwise the named file. The returned function closes it.
+func openDebugSearchOutput(path string) (io.Writer, func() error, error) {
+	if path == "-" {
+		return os.Stderr, func() error { return nil }, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create debug search output %s: %w", path, err)
+	}
+	return file, file.Close, nil
+}
+
+// dumpSearchResults writes one page of search results exactly as GitHub returned them,
+// before any PR details are fetched or filters applied. It does nothing if w is nil.
+func dumpSearchResults(w io.Writer, query string, page int, result *github.IssuesSearchResult) {
+	if w == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "# %s (page %d, %d results in total)\n", query, max(page, 1), result.GetTotal())
+	if result.GetIncompleteResults() {
+		fmt.Fprintf(w, "# GitHub reported incomplete results\n")
+	}
+	for _, issue := range result.Issues {
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\n", issue.GetNumber(), issue.GetState(), issue.GetUser().GetLogin(), issue.GetTitle())
+	}
+}
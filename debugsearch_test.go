@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpSearchResults(t *testing.T) {
+	result := &github.IssuesSearchResult{
+		Total:             github.Int(2),
+		IncompleteResults: github.Bool(true),
+		Issues: []*github.Issue{
+			{Number: github.Int(7), State: github.String("closed"), Title: github.String("Fix it"), User: &github.User{Login: github.String("someone")}},
+			{Number: github.Int(3), State: github.String("closed"), Title: github.String("Break it")},
+		},
+	}
+
+	var buf bytes.Buffer
+	dumpSearchResults(&buf, "repo:owner/repo is:pr", 0, result)
+	assert.Equal(t, "# repo:owner/repo is:pr (page 1, 2 results in total)\n"+
+		"# GitHub reported incomplete results\n"+
+		"#7\tclosed\tsomeone\tFix it\n"+
+		"#3\tclosed\t\tBreak it\n", buf.String())
+
+	assert.NotPanics(t, func() { dumpSearchResults(nil, "query", 1, result) })
+}
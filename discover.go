@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"gopkg.in/yaml.v3"
+)
+
+// searchResultCap is the most results GitHub search returns for one query, however many match
+const searchResultCap = 1000
+
+// repoContribution is a repository and how many of the users' merged PRs are in it
+type repoContribution struct {
+	Repository string
+	PRs        int
+}
+
+// listReposContributed prints the repositories the configured users merged PRs into during
+// the date range, most PRs first, and with writeRepos saves them as the config's repos
+func listReposContributed(ctx context.Context, svc *services, config Config, configFile string, writeRepos bool) error {
+	counts := make(map[string]int)
+	for _, username := range config.Usernames {
+		if err := discoverRepos(ctx, svc, config, username, config.SinceTime, config.UntilTime, counts); err != nil {
+			return fmt.Errorf("failed to discover repositories for %s: %w", username, err)
+		}
+	}
+
+	contributions := sortContributions(counts)
+	console.Infof("Found merged PRs in %d repositories", len(contributions))
+	var repos []string
+	for _, c := range contributions {
+		fmt.Printf("%s\t%d\n", c.Repository, c.PRs)
+		repos = append(repos, c.Repository)
+	}
+
+	if writeRepos {
+		if len(repos) == 0 {
+			return fmt.Errorf("no repositories found, leaving %s unchanged", configFile)
+		}
+		if err := writeReposToConfig(configFile, repos); err != nil {
+			return err
+		}
+		console.Infof("Wrote %d repositories to %s", len(repos), configFile)
+	}
+	return nil
+}
+
+// buildDiscoverySearchQuery builds the search query for a user's merged PRs in any
+// repository between since and until, limited the way the main search is
+func buildDiscoverySearchQuery(config Config, username string, since, until time.Time) string {
+	return fmt.Sprintf("is:pr is:merged author:%s %s", username, windowQualifierBetween(config, since, until).Text)
+}
+
+// discoverRepos adds the number of merged PRs the user has in each repository between
+// since and until to counts. Search only returns the first 1000 results of a query, so
+// a range with more matches than that is split in half and each half searched on its own.
+func discoverRepos(ctx context.Context, svc *services, config Config, username string, since, until time.Time, counts map[string]int) error {
+	client, err := svc.githubClient()
+	if err != nil {
+		return err
+	}
+
+	query := buildDiscoverySearchQuery(config, username, since, until)
+	opts := &github.SearchOptions{
+		Sort:        "created",
+		Order:       "desc",
+		ListOptions: github.ListOptions{PerPage: perPageLimit},
+	}
+
+	for {
+		result, resp, err := searchIssuesWithRetry(ctx, client, query, opts)
+		if err != nil {
+			return fmt.Errorf("failed to search PRs (page %d): %w", max(opts.Page, 1), err)
+		}
+		dumpSearchResults(svc.debugSearch, query, opts.Page, result)
+
+		// Split before counting anything from this range, so no PR is counted twice
+		if opts.Page <= 1 && result.GetTotal() > searchResultCap {
+			days := int(until.Sub(since).Hours() / 24)
+			if days >= 1 {
+				mid := since.AddDate(0, 0, days/2)
+				console.Infof("%d results for %s; splitting the date range", result.GetTotal(), query)
+				if err := discoverRepos(ctx, svc, config, username, since, mid, counts); err != nil {
+					return err
+				}
+				return discoverRepos(ctx, svc, config, username, mid.AddDate(0, 0, 1), until, counts)
+			}
+			console.Warnf("More than %d merged PRs on %s; only the first %d are counted", searchResultCap, since.Format(dateFormat), searchResultCap)
+		}
+
+		for _, issue := range result.Issues {
+			if repo := repoFromIssue(issue); repo != "" {
+				counts[repo]++
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// repoFromIssue returns the "owner/name" of the repository a search result is in
+func repoFromIssue(issue *github.Issue) string {
+	if repo := issue.GetRepository(); repo != nil && repo.GetFullName() != "" {
+		return repo.GetFullName()
+	}
+	// Search results only carry the API URL of the repository
+	_, repo, found := strings.Cut(issue.GetRepositoryURL(), "/repos/")
+	if !found {
+		return ""
+	}
+	return repo
+}
+
+// sortContributions orders repositories by PR count, most first, then by name
+func sortContributions(counts map[string]int) []repoContribution {
+	var contributions []repoContribution
+	for repo, prs := range counts {
+		contributions = append(contributions, repoContribution{Repository: repo, PRs: prs})
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].PRs != contributions[j].PRs {
+			return contributions[i].PRs > contributions[j].PRs
+		}
+		return contributions[i].Repository < contributions[j].Repository
+	})
+	return contributions
+}
+
+// writeReposToConfig sets the repos list in a config file, keeping the rest of the file,
+// its comments, and its permissions
+func writeReposToConfig(configFile string, repos []string) error {
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configFile, err)
+	}
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configFile, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s is not a YAML mapping", configFile)
+	}
+	root := doc.Content[0]
+
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, repo := range repos {
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: repo, Style: yaml.DoubleQuotedStyle})
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "repos" {
+			list.HeadComment = root.Content[i+1].HeadComment
+			root.Content[i+1] = list
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "repos"}, list)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	writer, err := getOutputWriter(configFile)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+	// A config holding a token may well be readable only by its owner
+	writer.mode = info.Mode().Perm()
+	if _, err := writer.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return writer.Commit()
+}
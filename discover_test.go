@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoFromIssue(t *testing.T) {
+	assert.Equal(t, "owner/repo", repoFromIssue(&github.Issue{RepositoryURL: github.String("https://api.github.com/repos/owner/repo")}))
+	assert.Equal(t, "owner/full", repoFromIssue(&github.Issue{Repository: &github.Repository{FullName: github.String("owner/full")}}))
+	assert.Equal(t, "", repoFromIssue(&github.Issue{}))
+}
+
+func TestSortContributions(t *testing.T) {
+	counts := map[string]int{"org/b": 2, "org/a": 2, "org/c": 5}
+	assert.Equal(t, []repoContribution{{"org/c", 5}, {"org/a", 2}, {"org/b", 2}}, sortContributions(counts))
+}
+
+func TestBuildDiscoverySearchQuery(t *testing.T) {
+	since := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, "is:pr is:merged author:someone created:2025-03-01..2025-04-01",
+		buildDiscoverySearchQuery(Config{}, "someone", since, until))
+	assert.Equal(t, "is:pr is:merged author:someone merged:2025-03-01..2025-04-01",
+		buildDiscoverySearchQuery(Config{SinceTag: "v2.3"}, "someone", since, until), "release windows go by merge date, like the main search")
+}
+
+func TestDiscoverReposSplitsLargeRanges(t *testing.T) {
+	var queries []string
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		queries = append(queries, query)
+		switch query {
+		case "is:pr is:merged author:someone created:2025-01-01..2025-01-31":
+			// Too many to list: the real API would stop at 1000
+			fmt.Fprint(w, `{"total_count": 1500, "items": [{"repository_url": "https://api.github.com/repos/org/ignored"}]}`)
+		case "is:pr is:merged author:someone created:2025-01-01..2025-01-16":
+			fmt.Fprint(w, `{"total_count": 2, "items": [{"repository_url": "https://api.github.com/repos/org/a"}, {"repository_url": "https://api.github.com/repos/org/b"}]}`)
+		case "is:pr is:merged author:someone created:2025-01-17..2025-01-31":
+			fmt.Fprint(w, `{"total_count": 1, "items": [{"repository_url": "https://api.github.com/repos/org/a"}]}`)
+		default:
+			t.Errorf("unexpected query %q", query)
+			fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+		}
+	}))
+
+	counts := make(map[string]int)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	svc := newServices(context.Background(), nil, nil)
+	svc.client = client
+	assert.NoError(t, discoverRepos(context.Background(), svc, Config{}, "someone", since, until, counts))
+	assert.Equal(t, map[string]int{"org/a": 2, "org/b": 1}, counts)
+	assert.Len(t, queries, 3)
+}
+
+func TestWriteReposToConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	t.Run("replaces repos and keeps comments", func(t *testing.T) {
+		original := "# My config\nusername: someone # me\noutput_dir: ./out\nrepos:\n  - old/repo\n"
+		if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		assert.NoError(t, writeReposToConfig(path, []string{"org/a", "org/b"}))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "# My config\nusername: someone # me\noutput_dir: ./out\nrepos:\n  - \"org/a\"\n  - \"org/b\"\n", string(data))
+	})
+
+	t.Run("keeps the file's permissions", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("username: someone\noutput_dir: ./out\n"), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		assert.NoError(t, os.Chmod(path, 0600))
+		assert.NoError(t, writeReposToConfig(path, []string{"org/a"}))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("adds repos", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte("username: someone\noutput_dir: ./out\n"), 0644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		assert.NoError(t, writeReposToConfig(path, []string{"org/a"}))
+		config, err := loadConfig(path, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"org/a"}, config.Repos)
+	})
+}
+
+func TestLoadConfigWithoutRepos(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("username: someone\noutput_dir: ./out\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	_, err := loadConfig(path, false)
+	assert.ErrorContains(t, err, "repos list cannot be empty")
+
+	config, err := loadConfig(path, true)
+	assert.NoError(t, err)
+	assert.Empty(t, config.Repos)
+}
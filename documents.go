@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	outputFormatPDF  = "pdf"
+	outputFormatDOCX = "docx"
+
+	defaultPandocPath = "pandoc"
+)
+
+// isDocumentFormat reports whether format is produced by converting the Markdown with pandoc
+func isDocumentFormat(format string) bool {
+	return format == outputFormatPDF || format == outputFormatDOCX
+}
+
+// documentFormats returns the requested output formats that are converted with pandoc
+func (c *Config) documentFormats() []string {
+	var formats []string
+	for _, format := range c.OutputFormats {
+		if isDocumentFormat(format) {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// checkDocumentConverter makes sure pandoc can be found when an output format needs it,
+// so that a missing dependency is reported before any PRs are fetched or summarized
+func checkDocumentConverter(config Config) error {
+	formats := config.documentFormats()
+	if len(formats) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath(config.PandocPath); err != nil {
+		return fmt.Errorf("output format '%s' needs pandoc (https://pandoc.org/installing.html), but '%s' was not found; install it or set pandoc_path: %w",
+			formats[0], config.PandocPath, err)
+	}
+	return nil
+}
+
+// convertDocument converts the Markdown file markdownPath to format with pandoc, next to
+// the original, and returns the path of the new file
+func convertDocument(ctx context.Context, markdownPath, format string, config Config) (string, error) {
+	outputPath := strings.TrimSuffix(markdownPath, ".md") + "." + format
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, config.PandocPath, "--from", "gfm", "--standalone", "--output", outputPath, markdownPath)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if format == outputFormatPDF && strings.Contains(msg, "pdflatex") {
+			msg += "\n(PDF output also needs a LaTeX engine such as pdflatex; install one or use the docx format)"
+		}
+		return "", fmt.Errorf("pandoc failed to convert %s to %s: %w\n%s", markdownPath, format, err, msg)
+	}
+	return outputPath, nil
+}
+
+// writeDocument converts markdownPath to format
+func writeDocument(ctx context.Context, markdownPath, format string, config Config) error {
+	outputPath, err := convertDocument(ctx, markdownPath, format, config)
+	if err != nil {
+		return err
+	}
+	console.Infof("Wrote %s", outputPath)
+	return nil
+}
+
+// writeDocuments converts markdownPath to every requested document format, if any
+func writeDocuments(ctx context.Context, markdownPath string, config Config) error {
+	for _, format := range config.documentFormats() {
+		if err := writeDocument(ctx, markdownPath, format, config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFakePandoc writes a script standing in for pandoc that records its arguments in
+// the output file
+func writeFakePandoc(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pandoc")
+	script := "#!/bin/sh\nfor last; do :; done\nwhile [ \"$1\" != \"--output\" ]; do shift; done\necho \"converted $last\" > \"$2\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake pandoc: %v", err)
+	}
+	return path
+}
+
+func TestCheckDocumentConverter(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "no-such-pandoc")
+
+	assert.NoError(t, checkDocumentConverter(Config{OutputFormats: []string{outputFormatHTML}, PandocPath: missing}))
+	assert.NoError(t, checkDocumentConverter(Config{OutputFormats: []string{outputFormatDOCX}, PandocPath: writeFakePandoc(t)}))
+
+	err := checkDocumentConverter(Config{OutputFormats: []string{outputFormatMarkdown, outputFormatPDF}, PandocPath: missing})
+	assert.ErrorContains(t, err, "output format 'pdf' needs pandoc")
+	assert.ErrorContains(t, err, "pandoc_path")
+}
+
+func TestWriteDocuments(t *testing.T) {
+	dir := t.TempDir()
+	summaryFile := filepath.Join(dir, "summary.md")
+	if err := os.WriteFile(summaryFile, []byte("# PR Summary\n"), 0644); err != nil {
+		t.Fatalf("failed to write summary: %v", err)
+	}
+
+	config := Config{OutputFormats: []string{outputFormatMarkdown, outputFormatDOCX, outputFormatPDF}, PandocPath: writeFakePandoc(t)}
+	assert.NoError(t, writeDocuments(context.Background(), summaryFile, config))
+	for _, name := range []string{"summary.docx", "summary.pdf"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, "converted "+summaryFile+"\n", string(data))
+	}
+
+	// Markdown and HTML don't need converting
+	config.OutputFormats = []string{outputFormatMarkdown, outputFormatHTML}
+	config.PandocPath = filepath.Join(dir, "no-such-pandoc")
+	assert.NoError(t, writeDocuments(context.Background(), summaryFile, config))
+}
+
+func TestParseOutputFormats(t *testing.T) {
+	base := func() Config {
+		return Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+	}
+
+	config := base()
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{outputFormatMarkdown}, config.OutputFormats)
+	assert.Equal(t, defaultPandocPath, config.PandocPath)
+
+	config = base()
+	config.OutputFormat = outputFormatPDF
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{outputFormatPDF}, config.OutputFormats)
+
+	config = base()
+	config.OutputFormats = []string{"Markdown", " json", "html"}
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{outputFormatMarkdown, outputFormatJSON, outputFormatHTML}, config.OutputFormats)
+	assert.True(t, config.wantsFormat(outputFormatHTML))
+	assert.Empty(t, config.documentFormats())
+
+	config = base()
+	config.OutputFormat = outputFormatHTML
+	config.OutputFormats = []string{outputFormatPDF}
+	assert.ErrorContains(t, config.Parse(), "output_format and output_formats cannot both be set")
+
+	config = base()
+	config.OutputFormats = []string{outputFormatHTML, "HTML"}
+	assert.ErrorContains(t, config.Parse(), "duplicate output format 'html'")
+
+	config = base()
+	config.OutputFormats = []string{outputFormatHTML}
+	config.DocumentIncludePRs = true
+	assert.ErrorContains(t, config.Parse(), "document_include_prs requires output format")
+
+	config = base()
+	config.OutputFormat = "rtf"
+	assert.ErrorContains(t, config.Parse(), "invalid output format 'rtf'")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// Exit codes, so that scripts can tell failure classes apart
+const (
+	exitOK          = 0
+	exitFailure     = 1   // anything not covered below
+	exitConfig      = 2   // invalid flags or configuration file
+	exitAuth        = 3   // no GitHub token, or GitHub rejected it
+	exitRateLimited = 4   // GitHub rate limit hit while fetching PRs
+	exitSummarizer  = 5   // summarizer could not be set up or failed
+	exitInterrupted = 130 // interrupted with -temp-output -cleanup, after removing the directory
+)
+
+// exitError attaches an exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode marks err as belonging to the failure class for code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCodeFor returns the process exit code for an error returned by run. Codes
+// attached with withExitCode win; otherwise GitHub API errors are classified by type.
+func exitCodeFor(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var codeErr *exitError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+	if isRateLimitError(err) {
+		return exitRateLimited
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusUnauthorized {
+		return exitAuth
+	}
+
+	return exitFailure
+}
+
+// isRateLimitError reports whether err is GitHub's primary or secondary rate limit
+func isRateLimitError(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	return errors.As(err, &rateErr) || errors.As(err, &abuseErr)
+}
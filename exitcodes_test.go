@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	unauthorized := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}}
+	notFound := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "no error", err: nil, expected: exitOK},
+		{name: "plain error", err: errors.New("boom"), expected: exitFailure},
+		{name: "config", err: withExitCode(exitConfig, errors.New("bad config")), expected: exitConfig},
+		{name: "wrapped code", err: fmt.Errorf("failed to process user: %w", withExitCode(exitSummarizer, errors.New("copilot failed"))), expected: exitSummarizer},
+		{name: "rate limit", err: fmt.Errorf("search failed: %w", &github.RateLimitError{}), expected: exitRateLimited},
+		{name: "secondary rate limit", err: &github.AbuseRateLimitError{}, expected: exitRateLimited},
+		{name: "bad credentials", err: fmt.Errorf("search failed: %w", unauthorized), expected: exitAuth},
+		{name: "other API error", err: notFound, expected: exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCodeFor(tt.err))
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Extractor pulls the interesting part out of a PR description
+type Extractor interface {
+	Extract(body string) string
+}
+
+// extractorFunc adapts a plain function to the Extractor interface
+type extractorFunc func(body string) string
+
+// Extract calls f(body)
+func (f extractorFunc) Extract(body string) string {
+	return f(body)
+}
+
+// Template headings the built-in extractors look for
+const (
+	accomplishHeading = "### What are you trying to accomplish?"
+	approachHeading   = "### What approach did you choose and why?"
+)
+
+// markerExtractor is an extractor that looks for template sections. Besides the text, it
+// returns the headings whose sections it couldn't use (absent or empty), which is
+// non-empty exactly when it fell back to more of the description than intended.
+type markerExtractor func(body string) (string, []string)
+
+// Extract calls f(body), dropping the missed headings
+func (f markerExtractor) Extract(body string) string {
+	text, _ := f(body)
+	return text
+}
+
+// extractorRegistry maps extractor names usable in the config to their implementations
+var extractorRegistry = map[string]Extractor{
+	"tss":           markerExtractor(extractTSSSection),
+	"dotcom":        markerExtractor(extractDotcomSection),
+	"first-heading": markerExtractor(extractFirstHeadingSectionWithMisses),
+	"passthrough":   extractorFunc(func(body string) string { return body }),
+}
+
+// defaultExtractors are the built-in repository mappings; config entries override them
+var defaultExtractors = map[string]string{
+	"github/token-scanning-service": "tss",
+	"github/github":                 "dotcom",
+}
+
+// extractorRule maps repositories matching Pattern (see path.Match) to an extractor
+type extractorRule struct {
+	Pattern string
+	Name    string
+}
+
+// buildExtractorRules merges configured extractors over the defaults, validates them, and
+// orders them so the most specific pattern is tried first: exact names before wildcards,
+// then longer patterns before shorter ones
+func buildExtractorRules(configured map[string]string) ([]extractorRule, error) {
+	merged := make(map[string]string, len(defaultExtractors)+len(configured))
+	for pattern, name := range defaultExtractors {
+		merged[pattern] = name
+	}
+	for pattern, name := range configured {
+		merged[strings.TrimSpace(pattern)] = strings.TrimSpace(name)
+	}
+
+	var rules []extractorRule
+	for pattern, name := range merged {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid extractor repository pattern '%s': %w", pattern, err)
+		}
+		if _, ok := extractorRegistry[name]; !ok {
+			return nil, fmt.Errorf("unknown extractor '%s' for '%s': expected one of %s", name, pattern, strings.Join(extractorNames(), ", "))
+		}
+		rules = append(rules, extractorRule{Pattern: pattern, Name: name})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		iWild := strings.ContainsAny(rules[i].Pattern, "*?[")
+		jWild := strings.ContainsAny(rules[j].Pattern, "*?[")
+		if iWild != jWild {
+			return !iWild
+		}
+		if len(rules[i].Pattern) != len(rules[j].Pattern) {
+			return len(rules[i].Pattern) > len(rules[j].Pattern)
+		}
+		return rules[i].Pattern < rules[j].Pattern
+	})
+
+	return rules, nil
+}
+
+// extractorNames returns the registered extractor names in sorted order
+func extractorNames() []string {
+	var names []string
+	for name := range extractorRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extractorFor returns the extractor for the first rule matching the repository,
+// or the passthrough extractor if none does
+func extractorFor(repository string, rules []extractorRule) Extractor {
+	return extractorRegistry[extractorNameFor(repository, rules)]
+}
+
+// extractorNameFor returns the name of the extractor extractorFor would return
+func extractorNameFor(repository string, rules []extractorRule) string {
+	for _, rule := range rules {
+		if matched, _ := path.Match(rule.Pattern, repository); matched {
+			return rule.Name
+		}
+	}
+	return "passthrough"
+}
+
+// getRepositorySpecificDescription returns the appropriate description text based on the repository
+func getRepositorySpecificDescription(repository, description string, rules []extractorRule) string {
+	return extractorFor(repository, rules).Extract(description)
+}
+
+// extractFirstHeadingSection extracts the content under the first Markdown heading, up to
+// the next heading. If there is no heading, or the section is empty, the original
+// description is returned.
+func extractFirstHeadingSection(description string) string {
+	text, _ := extractFirstHeadingSectionWithMisses(description)
+	return text
+}
+
+// extractFirstHeadingSectionWithMisses is extractFirstHeadingSection, also returning
+// "#" if it fell back to the original description
+func extractFirstHeadingSectionWithMisses(description string) (string, []string) {
+	lines := strings.Split(description, "\n")
+	var section []string
+	inSection := false
+
+	for _, line := range lines {
+		isHeading := strings.HasPrefix(strings.TrimSpace(line), "#")
+
+		if isHeading {
+			if inSection {
+				break // Stop at the next heading
+			}
+			inSection = true
+			continue // Skip the heading itself
+		}
+
+		if inSection {
+			section = append(section, line)
+		}
+	}
+
+	result := strings.TrimSpace(filterHTMLComments(strings.Join(section, "\n")))
+	if result == "" {
+		return description, []string{"#"}
+	}
+
+	return result, nil
+}
+
+// extractionMiss is a PR whose extractor fell back because it couldn't use the sections
+// under Markers
+type extractionMiss struct {
+	PR        PullRequestInfo
+	Extractor string
+	Markers   []string
+}
+
+// findExtractionMisses returns the PRs whose extractor fell back, as reported by the
+// extractor itself while extracting. PRs without a description are skipped.
+func findExtractionMisses(prs []PullRequestInfo, rules []extractorRule) []extractionMiss {
+	var misses []extractionMiss
+	for _, pr := range prs {
+		if strings.TrimSpace(pr.Description) == "" {
+			continue
+		}
+		name := extractorNameFor(pr.Repository, rules)
+		extractor, ok := extractorRegistry[name].(markerExtractor)
+		if !ok {
+			continue
+		}
+		if _, missed := extractor(pr.Description); len(missed) > 0 {
+			misses = append(misses, extractionMiss{PR: pr, Extractor: name, Markers: missed})
+		}
+	}
+	return misses
+}
+
+// outputExtractionDebug writes the -debug-extraction report of PRs whose extractor fell
+// back, and which sections it couldn't use
+func outputExtractionDebug(prs []PullRequestInfo, rules []extractorRule, outputFile string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing extraction debugging to %s", outputFile)
+	}
+
+	misses := findExtractionMisses(prs, rules)
+
+	fmt.Fprintf(writer, "# Extraction Debugging\n\n")
+	fmt.Fprintf(writer, "%d of %d pull requests were missing their extractor's sections or had them empty, so more of their description than intended was used.\n\n", len(misses), len(prs))
+
+	if len(misses) == 0 {
+		return writer.Commit()
+	}
+
+	fmt.Fprintf(writer, "| Pull Request | Repository | Extractor | Sections Missing or Empty |\n")
+	fmt.Fprintf(writer, "|--------------|------------|-----------|---------------------------|\n")
+	for _, miss := range misses {
+		title := strings.ReplaceAll(miss.PR.Title, "|", "\\|")
+		var markers []string
+		for _, marker := range miss.Markers {
+			markers = append(markers, "`"+marker+"`")
+		}
+		fmt.Fprintf(writer, "| [%s](%s) | %s | %s | %s |\n", title, miss.PR.URL, miss.PR.Repository, miss.Extractor, strings.Join(markers, ", "))
+	}
+
+	return writer.Commit()
+}
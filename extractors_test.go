@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExtractorRules(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		rules, err := buildExtractorRules(nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "This is the goal.", getRepositorySpecificDescription("github/token-scanning-service", "### What are you trying to accomplish?\n\nThis is the goal.\n\n### Other\n\nMore.", rules))
+		assert.Equal(t, "Plain body", getRepositorySpecificDescription("someone/else", "Plain body", rules))
+	})
+
+	t.Run("configured patterns and precedence", func(t *testing.T) {
+		rules, err := buildExtractorRules(map[string]string{
+			"myorg/*":       "first-heading",
+			"myorg/special": "passthrough",
+			"github/github": "passthrough",
+		})
+		assert.NoError(t, err)
+
+		body := "## Summary\n\nThe gist.\n\n## Details\n\nLots."
+		assert.Equal(t, "The gist.", getRepositorySpecificDescription("myorg/service", body, rules))
+		assert.Equal(t, body, getRepositorySpecificDescription("myorg/special", body, rules), "exact match beats wildcard")
+		assert.Equal(t, body, getRepositorySpecificDescription("github/github", body, rules), "config overrides defaults")
+	})
+
+	t.Run("unknown extractor", func(t *testing.T) {
+		_, err := buildExtractorRules(map[string]string{"owner/repo": "nope"})
+		assert.ErrorContains(t, err, "unknown extractor 'nope'")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := buildExtractorRules(map[string]string{"owner/[repo": "passthrough"})
+		assert.ErrorContains(t, err, "invalid extractor repository pattern")
+	})
+}
+
+func TestExtractFirstHeadingSection(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{
+			name:        "content under first heading",
+			description: "Preamble\n\n## Summary\n<!-- fill this in -->\nThe gist.\n\n## Testing\n\nRan it.",
+			expected:    "The gist.",
+		},
+		{
+			name:        "no heading",
+			description: "Just text.",
+			expected:    "Just text.",
+		},
+		{
+			name:        "empty first section",
+			description: "## Summary\n\n## Testing\n\nRan it.",
+			expected:    "## Summary\n\n## Testing\n\nRan it.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extractFirstHeadingSection(tt.description))
+		})
+	}
+}
+
+func TestExtractionDebug(t *testing.T) {
+	rules, err := buildExtractorRules(map[string]string{"owner/docs": "first-heading"})
+	assert.NoError(t, err)
+
+	prs := []PullRequestInfo{
+		{Title: "Matched", URL: "https://github.com/github/token-scanning-service/pull/1", Repository: "github/token-scanning-service",
+			Description: "### What are you trying to accomplish?\n\nThe goal."},
+		{Title: "Free-form | body", URL: "https://github.com/github/token-scanning-service/pull/2", Repository: "github/token-scanning-service",
+			Description: "Just some text."},
+		{Title: "Dotcom approach only", URL: "https://github.com/github/github/pull/3", Repository: "github/github",
+			Description: "Intro\n### What approach did you choose and why?\nBecause."},
+		{Title: "No headings", URL: "https://github.com/owner/docs/pull/4", Repository: "owner/docs", Description: "No headings here."},
+		{Title: "Empty", URL: "https://github.com/github/github/pull/5", Repository: "github/github"},
+		{Title: "Passthrough", URL: "https://github.com/owner/other/pull/6", Repository: "owner/other", Description: "Anything."},
+		{Title: "Empty TSS section", URL: "https://github.com/github/token-scanning-service/pull/7", Repository: "github/token-scanning-service",
+			Description: "### What are you trying to accomplish?\n\n### Notes\n\nSomething."},
+		{Title: "Dotcom marker mid-line", URL: "https://github.com/github/github/pull/8", Repository: "github/github",
+			Description: "> ### What are you trying to accomplish?\nThe goal."},
+		{Title: "Empty first heading", URL: "https://github.com/owner/docs/pull/9", Repository: "owner/docs", Description: "# Summary\n\n# Details\nMore."},
+	}
+
+	misses := findExtractionMisses(prs, rules)
+	var missed []string
+	for _, miss := range misses {
+		missed = append(missed, miss.PR.Title)
+	}
+	assert.Equal(t, []string{"Free-form | body", "Dotcom approach only", "No headings", "Empty TSS section", "Empty first heading"}, missed,
+		"listed exactly when the extractor fell back")
+	if assert.Len(t, misses, 5) {
+		assert.Equal(t, "tss", misses[0].Extractor)
+		assert.Equal(t, []string{accomplishHeading}, misses[0].Markers)
+		assert.Equal(t, []string{accomplishHeading}, misses[1].Markers, "the approach section was used")
+		assert.Equal(t, "first-heading", misses[2].Extractor)
+		assert.Equal(t, []string{"#"}, misses[4].Markers)
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "extraction-debug.md")
+	assert.NoError(t, outputExtractionDebug(prs, rules, outputFile))
+	content, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "5 of 9 pull requests were missing their extractor's sections or had them empty")
+	assert.Contains(t, string(content), "| [Free-form \\| body](https://github.com/github/token-scanning-service/pull/2) | github/token-scanning-service | tss | `### What are you trying to accomplish?` |\n")
+}
+
+func TestExtractDotcomSectionMisses(t *testing.T) {
+	_, missed := extractDotcomSection("Just text.")
+	assert.Equal(t, []string{accomplishHeading, approachHeading}, missed, "whole description used")
+	_, missed = extractDotcomSection("### What are you trying to accomplish?\nThe goal.")
+	assert.Nil(t, missed)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// Business hours are Monday through Friday, [businessDayStart, businessDayEnd)
+	businessDayStart = 9
+	businessDayEnd   = 17
+
+	// Policies for PRs whose merge time is unknown when filtering by business hours
+	unknownMergeTimeSkip    = "skip"
+	unknownMergeTimeInclude = "include"
+)
+
+// authorAssociations are the values GitHub reports for a PR author's association with
+// the repository
+var authorAssociations = []string{
+	"COLLABORATOR", "CONTRIBUTOR", "FIRST_TIMER", "FIRST_TIME_CONTRIBUTOR", "MANNEQUIN", "MEMBER", "NONE", "OWNER",
+}
+
+// prFilter decides whether a fetched PR is kept in the report. Keep returns whether the
+// PR passes along with a short human-readable reason, used by -explain.
+type prFilter struct {
+	Name string
+	Keep func(pr PullRequestInfo) (bool, string)
+}
+
+// filterResult records the outcome of one filter for one PR
+type filterResult struct {
+	Filter string
+	Passed bool
+	Reason string
+}
+
+// prDecision records why a candidate PR was or wasn't included
+type prDecision struct {
+	PR      PullRequestInfo
+	Source  string
+	Results []filterResult
+}
+
+// Included reports whether the PR passed every filter it was checked against
+func (d *prDecision) Included() bool {
+	for _, result := range d.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// decisionLog collects per-PR filter decisions in the order PRs were first seen
+type decisionLog struct {
+	decisions []*prDecision
+	byURL     map[string]*prDecision
+}
+
+// newDecisionLog creates an empty decision log
+func newDecisionLog() *decisionLog {
+	return &decisionLog{byURL: make(map[string]*prDecision)}
+}
+
+// decision returns the decision for a PR, creating it if needed
+func (l *decisionLog) decision(pr PullRequestInfo) *prDecision {
+	if d, ok := l.byURL[pr.URL]; ok {
+		return d
+	}
+
+	source := "author search"
+	if pr.CoAuthored {
+		source = "co-author search"
+	}
+	d := &prDecision{PR: pr, Source: source}
+	l.decisions = append(l.decisions, d)
+	l.byURL[pr.URL] = d
+	return d
+}
+
+// applyFilters returns the PRs that pass every filter. A PR is checked against all filters
+// even after one fails, so that decisions (if non-nil) shows every reason.
+func applyFilters(prs []PullRequestInfo, filters []prFilter, decisions *decisionLog) []PullRequestInfo {
+	var kept []PullRequestInfo
+	for _, pr := range prs {
+		var d *prDecision
+		if decisions != nil {
+			d = decisions.decision(pr)
+		}
+
+		keep := true
+		for _, filter := range filters {
+			passed, reason := filter.Keep(pr)
+			if d != nil {
+				d.Results = append(d.Results, filterResult{Filter: filter.Name, Passed: passed, Reason: reason})
+			}
+			keep = keep && passed
+		}
+
+		if keep {
+			kept = append(kept, pr)
+		}
+	}
+	return kept
+}
+
+// buildFilters returns the filters enabled by the configuration
+func buildFilters(config Config) []prFilter {
+	var filters []prFilter
+	if config.OnlyBusinessHours {
+		filters = append(filters, businessHoursFilter(config.BusinessLocation, config.UnknownMergeTime))
+	}
+	if config.usesReleaseTags() {
+		filters = append(filters, releaseWindowFilter(config.SinceTime, config.UntilTime))
+	}
+	if len(config.AuthorAssociations) > 0 {
+		filters = append(filters, authorAssociationFilter(config.AuthorAssociations))
+	}
+	return filters
+}
+
+// isDuringBusinessHours reports whether t, converted to loc, falls on a weekday
+// between 9:00 and 17:00
+func isDuringBusinessHours(t time.Time, loc *time.Location) bool {
+	local := t.In(loc)
+
+	switch local.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+
+	hour := local.Hour()
+	return hour >= businessDayStart && hour < businessDayEnd
+}
+
+// businessHoursFilter keeps only PRs merged during business hours in loc.
+// PRs with an unknown merge time are kept or dropped according to unknownPolicy.
+func businessHoursFilter(loc *time.Location, unknownPolicy string) prFilter {
+	return prFilter{
+		Name: "business-hours",
+		Keep: func(pr PullRequestInfo) (bool, string) {
+			if pr.MergedAt == nil {
+				return unknownPolicy == unknownMergeTimeInclude, fmt.Sprintf("merge time unknown (unknown_merge_time: %s)", unknownPolicy)
+			}
+
+			merged := pr.MergedAt.In(loc).Format("Mon 2006-01-02 15:04 MST")
+			if isDuringBusinessHours(*pr.MergedAt, loc) {
+				return true, fmt.Sprintf("merged %s, during business hours", merged)
+			}
+			return false, fmt.Sprintf("merged %s, outside business hours", merged)
+		},
+	}
+}
+
+// authorAssociationFilter keeps only PRs whose author had one of the allowed associations
+// with the repository. The allowed values must already be uppercase. Co-authored PRs are
+// kept, since their association is that of whoever opened them, not the user.
+func authorAssociationFilter(allowed []string) prFilter {
+	return prFilter{
+		Name: "author-association",
+		Keep: func(pr PullRequestInfo) (bool, string) {
+			if pr.CoAuthored {
+				return true, fmt.Sprintf("co-authored; opener's association %s not checked", pr.AuthorAssociation)
+			}
+			association := strings.ToUpper(pr.AuthorAssociation)
+			if association == "" {
+				return false, "author association unknown"
+			}
+			if slices.Contains(allowed, association) {
+				return true, fmt.Sprintf("author is %s", association)
+			}
+			return false, fmt.Sprintf("author is %s, not %s", association, strings.Join(allowed, " or "))
+		},
+	}
+}
+
+// diffFilter keeps only PRs that are not in a previous snapshot
+func diffFilter(previous []PullRequestInfo, previousPath string) prFilter {
+	seen := make(map[string]bool, len(previous))
+	for _, pr := range previous {
+		seen[pr.URL] = true
+	}
+
+	return prFilter{
+		Name: "diff-against",
+		Keep: func(pr PullRequestInfo) (bool, string) {
+			if seen[pr.URL] {
+				return false, fmt.Sprintf("already in %s", previousPath)
+			}
+			return true, fmt.Sprintf("new since %s", previousPath)
+		},
+	}
+}
+
+// writeDecisions logs the excluded PRs and writes decisions.md to outputDir. It does
+// nothing without -explain, when decisions is nil.
+func writeDecisions(decisions *decisionLog, outputDir string) error {
+	if decisions == nil {
+		return nil
+	}
+	for _, d := range decisions.decisions {
+		if !d.Included() {
+			console.Infof("Excluded %s (%s)", d.PR.URL, d.Source)
+		}
+	}
+	if err := outputDecisions(decisions, filepath.Join(outputDir, "decisions.md")); err != nil {
+		return fmt.Errorf("error writing filter decisions: %w", err)
+	}
+	return nil
+}
+
+// outputDecisions writes the -explain report of which PRs were included and why
+func outputDecisions(decisions *decisionLog, outputFile string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing filter decisions to %s", outputFile)
+	}
+
+	included := 0
+	for _, d := range decisions.decisions {
+		if d.Included() {
+			included++
+		}
+	}
+
+	fmt.Fprintf(writer, "# Filter Decisions\n\n")
+	fmt.Fprintf(writer, "%d candidate pull requests, %d included.\n\n", len(decisions.decisions), included)
+
+	if len(decisions.decisions) == 0 {
+		return writer.Commit()
+	}
+
+	fmt.Fprintf(writer, "| Pull Request | Repository | Found By | Result | Details |\n")
+	fmt.Fprintf(writer, "|--------------|------------|----------|--------|---------|\n")
+	for _, d := range decisions.decisions {
+		result := "✅ Included"
+		var details []string
+		for _, r := range d.Results {
+			mark := "✓"
+			if !r.Passed {
+				mark = "✗"
+				result = "❌ Excluded"
+			}
+			details = append(details, fmt.Sprintf("%s %s: %s", mark, r.Filter, r.Reason))
+		}
+		if len(details) == 0 {
+			details = append(details, "no filters enabled")
+		}
+
+		title := strings.ReplaceAll(d.PR.Title, "|", "\\|")
+		fmt.Fprintf(writer, "| [%s](%s) | %s | %s | %s | %s |\n",
+			title, d.PR.URL, d.PR.Repository, d.Source, result, strings.Join(details, "<br>"))
+	}
+
+	return writer.Commit()
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDuringBusinessHours(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		loc      *time.Location
+		expected bool
+	}{
+		{
+			name:     "weekday morning",
+			time:     time.Date(2025, 6, 4, 10, 30, 0, 0, time.UTC), // Wednesday
+			loc:      time.UTC,
+			expected: true,
+		},
+		{
+			name:     "start of business day is included",
+			time:     time.Date(2025, 6, 4, 9, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: true,
+		},
+		{
+			name:     "end of business day is excluded",
+			time:     time.Date(2025, 6, 4, 17, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: false,
+		},
+		{
+			name:     "weekday early morning",
+			time:     time.Date(2025, 6, 4, 8, 59, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: false,
+		},
+		{
+			name:     "saturday",
+			time:     time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: false,
+		},
+		{
+			name:     "sunday",
+			time:     time.Date(2025, 6, 8, 12, 0, 0, 0, time.UTC),
+			loc:      time.UTC,
+			expected: false,
+		},
+		{
+			name:     "converted into business hours by timezone",
+			time:     time.Date(2025, 6, 4, 20, 0, 0, 0, time.UTC), // 16:00 EDT
+			loc:      newYork,
+			expected: true,
+		},
+		{
+			name:     "converted out of business hours by timezone",
+			time:     time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC), // 06:00 EDT
+			loc:      newYork,
+			expected: false,
+		},
+		{
+			name:     "converted onto a weekend by timezone",
+			time:     time.Date(2025, 6, 7, 2, 0, 0, 0, time.UTC), // Friday 22:00 EDT
+			loc:      newYork,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isDuringBusinessHours(tt.time, tt.loc))
+		})
+	}
+}
+
+func titles(prs []PullRequestInfo) []string {
+	var result []string
+	for _, pr := range prs {
+		result = append(result, pr.Title)
+	}
+	return result
+}
+
+func TestBusinessHoursFilter(t *testing.T) {
+	inHours := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+	outOfHours := time.Date(2025, 6, 7, 10, 0, 0, 0, time.UTC)
+	prs := []PullRequestInfo{
+		{Title: "in hours", URL: "1", MergedAt: &inHours},
+		{Title: "out of hours", URL: "2", MergedAt: &outOfHours},
+		{Title: "unknown", URL: "3"},
+	}
+
+	t.Run("skip unknown merge time", func(t *testing.T) {
+		result := applyFilters(prs, []prFilter{businessHoursFilter(time.UTC, unknownMergeTimeSkip)}, nil)
+		assert.Equal(t, []string{"in hours"}, titles(result))
+	})
+
+	t.Run("include unknown merge time", func(t *testing.T) {
+		result := applyFilters(prs, []prFilter{businessHoursFilter(time.UTC, unknownMergeTimeInclude)}, nil)
+		assert.Equal(t, []string{"in hours", "unknown"}, titles(result))
+	})
+}
+
+func TestAuthorAssociationFilter(t *testing.T) {
+	prs := []PullRequestInfo{
+		{Title: "member", URL: "1", AuthorAssociation: "MEMBER"},
+		{Title: "owner", URL: "2", AuthorAssociation: "owner"},
+		{Title: "contributor", URL: "3", AuthorAssociation: "CONTRIBUTOR"},
+		{Title: "unknown", URL: "4"},
+		{Title: "co-authored", URL: "5", AuthorAssociation: "FIRST_TIME_CONTRIBUTOR", CoAuthored: true},
+	}
+
+	decisions := newDecisionLog()
+	result := applyFilters(prs, []prFilter{authorAssociationFilter([]string{"MEMBER", "OWNER"})}, decisions)
+	assert.Equal(t, []string{"member", "owner", "co-authored"}, titles(result))
+	assert.Equal(t, "author is CONTRIBUTOR, not MEMBER or OWNER", decisions.byURL["3"].Results[0].Reason)
+}
+
+func TestParseAuthorAssociations(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, AuthorAssociations: []string{"member", " Owner "}}
+	assert.NoError(t, config.Parse())
+	assert.Equal(t, []string{"MEMBER", "OWNER"}, config.AuthorAssociations)
+
+	config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, AuthorAssociations: []string{"admin"}}
+	assert.ErrorContains(t, config.Parse(), "invalid author association 'admin'")
+}
+
+func TestDiffFilter(t *testing.T) {
+	pr := func(n string) PullRequestInfo {
+		return PullRequestInfo{Title: "PR " + n, URL: "https://github.com/owner/repo/pull/" + n}
+	}
+
+	tests := []struct {
+		name     string
+		current  []PullRequestInfo
+		previous []PullRequestInfo
+		expected []PullRequestInfo
+	}{
+		{
+			name:     "no previous report",
+			current:  []PullRequestInfo{pr("1"), pr("2")},
+			previous: nil,
+			expected: []PullRequestInfo{pr("1"), pr("2")},
+		},
+		{
+			name:     "some new",
+			current:  []PullRequestInfo{pr("3"), pr("2"), pr("1")},
+			previous: []PullRequestInfo{pr("1"), pr("2")},
+			expected: []PullRequestInfo{pr("3")},
+		},
+		{
+			name:     "nothing new",
+			current:  []PullRequestInfo{pr("1")},
+			previous: []PullRequestInfo{pr("1"), pr("2")},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := applyFilters(tt.current, []prFilter{diffFilter(tt.previous, "old.json")}, nil)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestApplyFiltersRecordsDecisions(t *testing.T) {
+	inHours := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+	outOfHours := time.Date(2025, 6, 7, 10, 0, 0, 0, time.UTC)
+	prs := []PullRequestInfo{
+		{Title: "kept", URL: "https://github.com/owner/repo/pull/1", MergedAt: &inHours},
+		{Title: "weekend and old", URL: "https://github.com/owner/repo/pull/2", MergedAt: &outOfHours, CoAuthored: true},
+	}
+	previous := []PullRequestInfo{{URL: "https://github.com/owner/repo/pull/2"}}
+
+	decisions := newDecisionLog()
+	kept := applyFilters(prs, []prFilter{businessHoursFilter(time.UTC, unknownMergeTimeSkip)}, decisions)
+	kept = applyFilters(kept, []prFilter{diffFilter(previous, "old.json")}, decisions)
+	assert.Equal(t, []string{"kept"}, titles(kept))
+
+	if assert.Len(t, decisions.decisions, 2) {
+		first := decisions.decisions[0]
+		assert.True(t, first.Included())
+		assert.Equal(t, "author search", first.Source)
+		assert.Equal(t, []string{"business-hours", "diff-against"}, []string{first.Results[0].Filter, first.Results[1].Filter})
+
+		second := decisions.decisions[1]
+		assert.False(t, second.Included())
+		assert.Equal(t, "co-author search", second.Source)
+		if assert.Len(t, second.Results, 1, "filtered PRs are not checked by later stages") {
+			assert.Equal(t, "merged Sat 2025-06-07 10:00 UTC, outside business hours", second.Results[0].Reason)
+		}
+	}
+}
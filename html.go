@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+const (
+	// Report formats
+	outputFormatMarkdown = "markdown"
+	outputFormatJSON     = "json"
+	outputFormatHTML     = "html"
+)
+
+// outputFormats are the values accepted in output_formats
+var outputFormats = []string{outputFormatMarkdown, outputFormatJSON, outputFormatHTML, outputFormatPDF, outputFormatDOCX}
+
+// userProfile is the public GitHub profile shown in HTML reports
+type userProfile struct {
+	Login     string
+	Name      string
+	AvatarURL string
+	HTMLURL   string
+}
+
+// DisplayName returns "Name (@login)", or just "@login" if the user has no name set
+func (p *userProfile) DisplayName() string {
+	if p.Name == "" {
+		return "@" + p.Login
+	}
+	return fmt.Sprintf("%s (@%s)", p.Name, p.Login)
+}
+
+// profileCache looks up GitHub user profiles, remembering each result for the rest of the run
+type profileCache struct {
+	getClient func() (*github.Client, error)
+	profiles  map[string]*userProfile
+}
+
+// newProfileCache creates an empty profile cache that uses getClient for lookups
+func newProfileCache(getClient func() (*github.Client, error)) *profileCache {
+	return &profileCache{getClient: getClient, profiles: make(map[string]*userProfile)}
+}
+
+// Get returns the profile for login. If the lookup fails, it falls back to the
+// profile URL and avatar the GitHub host serves for every login.
+func (c *profileCache) Get(ctx context.Context, login string) *userProfile {
+	if profile, ok := c.profiles[login]; ok {
+		return profile
+	}
+
+	profile := &userProfile{
+		Login:     login,
+		AvatarURL: fmt.Sprintf("https://%s/%s.png", githubHost(), login),
+		HTMLURL:   fmt.Sprintf("https://%s/%s", githubHost(), login),
+	}
+
+	client, err := c.getClient()
+	if err == nil {
+		var user *github.User
+		user, _, err = client.Users.Get(ctx, login)
+		if err == nil {
+			profile.Name = user.GetName()
+			if user.GetAvatarURL() != "" {
+				profile.AvatarURL = user.GetAvatarURL()
+			}
+			if user.GetHTMLURL() != "" {
+				profile.HTMLURL = user.GetHTMLURL()
+			}
+		}
+	}
+	if err != nil {
+		console.Warnf("Failed to look up GitHub profile for %s: %v", login, err)
+	}
+
+	c.profiles[login] = profile
+	return profile
+}
+
+// htmlReport is the data for reportHTMLTemplate
+type htmlReport struct {
+	Title     string
+	Summary   string
+	EmptyText string
+	Stats     []htmlAuthorStats
+	Sections  []htmlSection
+}
+
+// htmlAuthorStats is one row of the team stats table
+type htmlAuthorStats struct {
+	Profile *userProfile
+	PRs     int
+	Repos   int
+}
+
+// htmlSection is the PRs of one author
+type htmlSection struct {
+	Profile *userProfile
+	Repos   []htmlRepo
+}
+
+// htmlRepo is the PRs in one repository
+type htmlRepo struct {
+	Name string
+	URL  string // only set for repositories with a display name
+	PRs  []htmlPR
+}
+
+// htmlPR is a single rendered PR
+type htmlPR struct {
+	Title        string
+	Repository   string // only set in a collapsed Miscellaneous section
+	URL          string
+	Created      string
+	Merged       string
+	OpenedBy     string
+	RevertedBy   string
+	ImpactTag    string
+	Description  string
+	NoneProvided bool
+	Truncated    bool
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+a { color: #0969da; }
+.author { display: flex; align-items: center; gap: 0.75rem; }
+.avatar { border-radius: 50%; }
+.pr { border-bottom: 1px solid #d1d9e0; padding-bottom: 1rem; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.25rem 0.75rem; border: 1px solid #d1d9e0; }
+.description { white-space: pre-wrap; }
+.tag { font-size: 0.8em; font-weight: normal; border: 1px solid #d1d9e0; border-radius: 1em; padding: 0 0.5em; }
+.reverted { color: #9a6700; font-size: 0.8em; }
+.empty { font-style: italic; color: #59636e; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}}</p>
+{{- if .Stats}}
+<table>
+<tr><th>Author</th><th>Merged PRs</th><th>Repositories</th></tr>
+{{- range .Stats}}
+<tr><td><a href="{{.Profile.HTMLURL}}">{{.Profile.DisplayName}}</a></td><td>{{.PRs}}</td><td>{{.Repos}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Sections}}
+<section>
+{{- with .Profile}}
+<h2 class="author"><img class="avatar" src="{{.AvatarURL}}" alt="" width="48" height="48"> <a href="{{.HTMLURL}}">{{.DisplayName}}</a></h2>
+{{- end}}
+{{- if not .Repos}}
+<p class="empty">{{$.EmptyText}}</p>
+{{- end}}
+{{- range .Repos}}
+<h3>{{if .URL}}<a href="{{.URL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</h3>
+{{- range .PRs}}
+<article class="pr">
+<h4><a href="{{.URL}}">{{.Title}}</a>{{if .ImpactTag}} <span class="tag">{{.ImpactTag}}</span>{{end}}{{if .RevertedBy}} <span class="reverted">⚠ later reverted</span>{{end}}</h4>
+<table>
+{{- if .Repository}}
+<tr><th>Repository</th><td>{{.Repository}}</td></tr>
+{{- end}}
+<tr><th>Created</th><td>{{.Created}}</td></tr>
+<tr><th>Link</th><td><a href="{{.URL}}">{{.URL}}</a></td></tr>
+{{- if .OpenedBy}}
+<tr><th>Role</th><td>Co-author (PR opened by {{.OpenedBy}})</td></tr>
+{{- end}}
+<tr><th>Merged</th><td>{{if .Merged}}{{.Merged}}{{else}}<span class="empty">Not available</span>{{end}}</td></tr>
+{{- if .RevertedBy}}
+<tr><th>Reverted by</th><td><a href="{{.RevertedBy}}">{{.RevertedBy}}</a></td></tr>
+{{- end}}
+</table>
+{{- if .NoneProvided}}
+<p class="empty">{{.Description}}</p>
+{{- else}}
+<div class="description">{{.Description}}{{if .Truncated}} … [truncated] <a href="{{.URL}}">Read the full description</a>{{end}}</div>
+{{- end}}
+</article>
+{{- end}}
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlSectionFor converts one author's PRs into template data
+func htmlSectionFor(profile *userProfile, prs []PullRequestInfo, config Config) htmlSection {
+	section := htmlSection{Profile: profile}
+	for _, group := range orderRepoGroups(prs, config) {
+		repo := htmlRepo{Name: group.Repository}
+		if name, ok := config.RepoDisplayNames[group.Repository]; ok && !group.Collapsed {
+			repo.Name = name
+			repo.URL = repoURL(group)
+		}
+		for _, pr := range group.PRs {
+			item := htmlPR{
+				Title:   pr.Title,
+				URL:     pr.URL,
+				Created: pr.CreatedAt.Format("2006-01-02 15:04:05"),
+			}
+			if pr.MergedAt != nil {
+				item.Merged = pr.MergedAt.Format("2006-01-02 15:04:05")
+			}
+			if pr.CoAuthored {
+				item.OpenedBy = pr.Author
+			}
+			if group.Collapsed {
+				item.Repository = repoLabel(pr.Repository, config)
+			}
+			item.RevertedBy = pr.RevertedBy
+			item.ImpactTag = pr.ImpactTag
+			if strings.TrimSpace(pr.Description) != "" {
+				item.Description, item.Truncated = extractDescription(pr, config)
+			} else {
+				item.Description = plainText(config.EmptyDescriptionText)
+				item.NoneProvided = true
+			}
+			repo.PRs = append(repo.PRs, item)
+		}
+		section.Repos = append(section.Repos, repo)
+	}
+	return section
+}
+
+// plainText strips the emphasis markers the empty-state Markdown is usually wrapped in
+func plainText(markdown string) string {
+	return strings.Trim(strings.TrimSpace(markdown), "*_")
+}
+
+// outputPRsHTML outputs the PR information as a standalone HTML page, headed by the
+// author's avatar and a link to their profile
+func outputPRsHTML(prs []PullRequestInfo, profile *userProfile, outputFile string, config Config) error {
+	report := htmlReport{
+		Title:     "Merged Pull Requests",
+		Summary:   fmt.Sprintf("Found %d merged pull requests.", len(prs)),
+		EmptyText: plainText(config.NoPRsText),
+		Sections:  []htmlSection{htmlSectionFor(profile, prs, config)},
+	}
+	return writeHTMLReport(report, outputFile)
+}
+
+// outputTeamReportHTML is the HTML counterpart of outputTeamReport, with each author's
+// avatar in their section header
+func outputTeamReportHTML(reports []userReport, outputFile string, config Config) error {
+	totalPRs := 0
+	allRepos := make(map[string]bool)
+	report := htmlReport{
+		Title:     "Team Report",
+		EmptyText: plainText(config.NoPRsText),
+	}
+	for _, userReport := range reports {
+		repos := make(map[string]bool)
+		for _, pr := range userReport.PRs {
+			repos[pr.Repository] = true
+			allRepos[pr.Repository] = true
+		}
+		totalPRs += len(userReport.PRs)
+
+		report.Stats = append(report.Stats, htmlAuthorStats{Profile: userReport.Profile, PRs: len(userReport.PRs), Repos: len(repos)})
+		report.Sections = append(report.Sections, htmlSectionFor(userReport.Profile, userReport.PRs, config))
+	}
+	report.Summary = fmt.Sprintf("Found %d merged pull requests from %d authors across %d repositories.", totalPRs, len(reports), len(allRepos))
+
+	return writeHTMLReport(report, outputFile)
+}
+
+// writeHTMLReport renders the report template to outputFile
+func writeHTMLReport(report htmlReport, outputFile string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing HTML report to %s", outputFile)
+	}
+
+	if err := reportHTMLTemplate.Execute(writer, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	return writer.Commit()
+}
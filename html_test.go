@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserProfileDisplayName(t *testing.T) {
+	assert.Equal(t, "@octocat", (&userProfile{Login: "octocat"}).DisplayName())
+	assert.Equal(t, "The Octocat (@octocat)", (&userProfile{Login: "octocat", Name: "The Octocat"}).DisplayName())
+}
+
+func TestProfileCacheFallback(t *testing.T) {
+	t.Setenv("GH_HOST", "github.example.com")
+	cache := newProfileCache(func() (*github.Client, error) { return nil, errors.New("no token") })
+
+	profile := cache.Get(context.Background(), "octocat")
+	assert.Equal(t, &userProfile{
+		Login:     "octocat",
+		AvatarURL: "https://github.example.com/octocat.png",
+		HTMLURL:   "https://github.example.com/octocat",
+	}, profile)
+}
+
+func TestPlainText(t *testing.T) {
+	assert.Equal(t, "No description provided.", plainText(defaultEmptyDescriptionText))
+	assert.Equal(t, "Nothing merged.", plainText("_Nothing merged._"))
+	assert.Equal(t, "Plain", plainText("Plain"))
+}
+
+func TestOutputPRsHTML(t *testing.T) {
+	config := Config{Username: "octocat", OutputDir: "out", Repos: []string{"owner/repo"}, OutputFormats: []string{outputFormatHTML}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	profile := &userProfile{
+		Login:     "octocat",
+		Name:      "The Octocat",
+		AvatarURL: "https://avatars.githubusercontent.com/u/583231",
+		HTMLURL:   "https://github.com/octocat",
+	}
+
+	t.Run("renders avatar, profile link and escaped PRs", func(t *testing.T) {
+		merged := time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC)
+		prs := []PullRequestInfo{
+			{
+				Repository:  "owner/repo",
+				Title:       "Fix <script> injection",
+				Description: "Escapes & sanitizes",
+				URL:         "https://github.com/owner/repo/pull/1",
+				CreatedAt:   merged.Add(-time.Hour),
+				MergedAt:    &merged,
+			},
+			{Repository: "owner/repo", Title: "Empty PR", URL: "https://github.com/owner/repo/pull/2"},
+		}
+
+		path := filepath.Join(t.TempDir(), "prs.html")
+		assert.NoError(t, outputPRsHTML(prs, profile, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		html := string(data)
+
+		assert.Contains(t, html, `<img class="avatar" src="https://avatars.githubusercontent.com/u/583231"`)
+		assert.Contains(t, html, `<a href="https://github.com/octocat">The Octocat (@octocat)</a>`)
+		assert.Contains(t, html, "Fix &lt;script&gt; injection")
+		assert.NotContains(t, html, "<script>")
+		assert.Contains(t, html, "Escapes &amp; sanitizes")
+		assert.Contains(t, html, "No description provided.")
+		assert.Contains(t, html, "2025-05-02 10:00:00")
+		assert.Contains(t, html, "Found 2 merged pull requests.")
+	})
+
+	t.Run("no PRs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prs.html")
+		assert.NoError(t, outputPRsHTML(nil, profile, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "No merged PRs found.")
+	})
+}
+
+func TestOutputTeamReportHTML(t *testing.T) {
+	config := Config{Usernames: []string{"alice", "bob"}, CombineUsers: true, OutputDir: "out", Repos: []string{"owner/repo"}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	reports := []userReport{
+		{
+			Username: "alice",
+			Profile:  &userProfile{Login: "alice", AvatarURL: "https://github.com/alice.png", HTMLURL: "https://github.com/alice"},
+			PRs:      []PullRequestInfo{{Repository: "owner/repo", Title: "Alice PR", URL: "https://github.com/owner/repo/pull/1"}},
+		},
+		{
+			Username: "bob",
+			Profile:  &userProfile{Login: "bob", AvatarURL: "https://github.com/bob.png", HTMLURL: "https://github.com/bob"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "team-report.html")
+	assert.NoError(t, outputTeamReportHTML(reports, path, config))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	html := string(data)
+
+	assert.Contains(t, html, "Found 1 merged pull requests from 2 authors across 1 repositories.")
+	assert.Contains(t, html, `src="https://github.com/alice.png"`)
+	assert.Contains(t, html, `src="https://github.com/bob.png"`)
+	assert.Contains(t, html, "Alice PR")
+	assert.Contains(t, html, "No merged PRs found.")
+}
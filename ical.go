@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// icalTimeFormat is the UTC date-time format of iCalendar (RFC 5545)
+const icalTimeFormat = "20060102T150405Z"
+
+// icalLineLimit is the most octets allowed on one iCalendar content line
+const icalLineLimit = 75
+
+// outputICal writes each merged PR as an event at its merge time in an iCalendar file,
+// so that the shipping cadence can be seen in a calendar app. PRs whose merge time is
+// unknown are skipped.
+func outputICal(prs []PullRequestInfo, outputFile string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing calendar to %s", outputFile)
+	}
+
+	writeICalLine(writer, "BEGIN:VCALENDAR")
+	writeICalLine(writer, "VERSION:2.0")
+	writeICalLine(writer, "PRODID:-//employment-justifier//Merged PRs//EN")
+	writeICalLine(writer, "CALSCALE:GREGORIAN")
+
+	skipped := 0
+	for _, pr := range prs {
+		if pr.MergedAt == nil {
+			skipped++
+			continue
+		}
+		merged := pr.MergedAt.UTC().Format(icalTimeFormat)
+
+		writeICalLine(writer, "BEGIN:VEVENT")
+		writeICalLine(writer, "UID:"+escapeICalText(pr.URL)+"@employment-justifier")
+		// The merge time rather than the time of the run, so rerunning doesn't change the file
+		writeICalLine(writer, "DTSTAMP:"+merged)
+		writeICalLine(writer, "DTSTART:"+merged)
+		writeICalLine(writer, "SUMMARY:"+escapeICalText(pr.Title))
+		writeICalLine(writer, "DESCRIPTION:"+escapeICalText(fmt.Sprintf("%s\n%s", pr.Repository, pr.URL)))
+		writeICalLine(writer, "URL:"+pr.URL)
+		writeICalLine(writer, "END:VEVENT")
+	}
+
+	writeICalLine(writer, "END:VCALENDAR")
+
+	if skipped > 0 {
+		console.Warnf("Left %d PRs with unknown merge times out of the calendar", skipped)
+	}
+	return writer.Commit()
+}
+
+// escapeICalText escapes a value of the iCalendar TEXT type
+func escapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// writeICalLine writes one content line, folded so that no physical line is longer than
+// icalLineLimit octets. Folds never split a UTF-8 character.
+func writeICalLine(writer io.Writer, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(writer, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = icalLineLimit - 1
+	}
+	fmt.Fprintf(writer, "%s\r\n", line)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputICal(t *testing.T) {
+	merged := time.Date(2025, 5, 2, 10, 30, 0, 0, time.FixedZone("EDT", -4*60*60))
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", Title: "Fix parsing; handle commas, too", URL: "https://github.com/owner/repo/pull/1", MergedAt: &merged},
+		{Repository: "owner/repo", Title: "Unknown merge time", URL: "https://github.com/owner/repo/pull/2"},
+	}
+
+	path := filepath.Join(t.TempDir(), "prs.ics")
+	assert.NoError(t, outputICal(prs, path))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//employment-justifier//Merged PRs//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:https://github.com/owner/repo/pull/1@employment-justifier",
+		"DTSTAMP:20250502T143000Z",
+		"DTSTART:20250502T143000Z",
+		`SUMMARY:Fix parsing\; handle commas\, too`,
+		`DESCRIPTION:owner/repo\nhttps://github.com/owner/repo/pull/1`,
+		"URL:https://github.com/owner/repo/pull/1",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), string(data))
+}
+
+func TestWriteICalLine(t *testing.T) {
+	fold := func(line string) string {
+		var buf strings.Builder
+		writeICalLine(&buf, line)
+		return buf.String()
+	}
+
+	assert.Equal(t, "SUMMARY:short\r\n", fold("SUMMARY:short"))
+
+	long := "SUMMARY:" + strings.Repeat("a", 150)
+	folded := fold(long)
+	physical := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	assert.Len(t, physical, 3)
+	for _, line := range physical {
+		assert.LessOrEqual(t, len(line), icalLineLimit)
+	}
+	assert.Equal(t, long, strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""))
+
+	// Multi-byte characters are never split across lines
+	accents := "SUMMARY:" + strings.Repeat("é", 60)
+	for _, line := range strings.Split(strings.TrimSuffix(fold(accents), "\r\n"), "\r\n") {
+		assert.True(t, utf8.ValidString(line))
+		assert.LessOrEqual(t, len(line), icalLineLimit)
+	}
+}
@@ -2,18 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/google/go-github/v56/github"
 	"github.com/schollz/progressbar/v3"
-	"golang.org/x/oauth2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,17 +28,28 @@ const (
 	// Date format for GitHub API
 	dateFormat = "2006-01-02"
 
+	// Deepest heading level Markdown supports
+	maxHeadingLevel = 6
+
 	// Progress bar and pagination settings
 	perPageLimit = 100
 
+	// Default Markdown for empty states in the PR report
+	defaultEmptyDescriptionText = "*No description provided.*"
+	defaultNoPRsText            = "*No merged PRs found.*"
+
 	defaultPrompt = `An employee is undergoing a performance review. They have contributed to the company by merging several pull requests.
-Describe their major contributions based on the PR descriptions in @%s. Be sure to emphasize the impact of their work and any significant features or improvements they introduced.
+Describe their major contributions based on the PR descriptions in %s. Be sure to emphasize the impact of their work and any significant features or improvements they introduced.
+Include links to PRs. Don't write any files. For each contribution, include an approximate date range during which the work was done.`
+
+	teamPrompt = `A team of employees is being reviewed. Together they have contributed to the company by merging several pull requests.
+Describe the team's major contributions based on the PR descriptions in %s, which are grouped by author. Be sure to emphasize the impact of the work, any significant features or improvements introduced, and who drove each of them.
 Include links to PRs. Don't write any files. For each contribution, include an approximate date range during which the work was done.`
 )
 
 // Config holds the complete application configuration
 type Config struct {
-	Username    string   `yaml:"username"`
+	Username    string   `yaml:"username,omitempty"`
 	Since       string   `yaml:"since,omitempty"`
 	Until       string   `yaml:"until,omitempty"`
 	Days        int      `yaml:"days,omitempty"`
@@ -42,10 +57,186 @@ type Config struct {
 	ExtraPrompt string   `yaml:"extra-prompt,omitempty"`
 	Repos       []string `yaml:"repos"`
 
+	// Date window anchored to releases of one repository (optional, replaces since and/or until)
+	ReleaseRepo string `yaml:"release_repo,omitempty"`
+	SinceTag    string `yaml:"since_tag,omitempty"`
+	UntilTag    string `yaml:"until_tag,omitempty"`
+
+	// Manager mode: several users, each with their own output subdirectory
+	Usernames    []string `yaml:"usernames,omitempty"`
+	CombineUsers bool     `yaml:"combine_users,omitempty"`
+	TeamSummary  bool     `yaml:"team_summary,omitempty"`
+
+	// Business hours filtering (optional)
+	OnlyBusinessHours bool   `yaml:"only_business_hours,omitempty"`
+	BusinessTimezone  string `yaml:"business_timezone,omitempty"`
+	UnknownMergeTime  string `yaml:"unknown_merge_time,omitempty"`
+
+	// Only keep PRs whose author had one of these associations with the repo, e.g. MEMBER (optional)
+	AuthorAssociations []string `yaml:"author_associations,omitempty"`
+
+	// Only keep PRs changing paths that CODEOWNERS assigns to the user or one of
+	// CodeownersTeams, e.g. "myorg/payments" (optional)
+	CodeownersFilter bool     `yaml:"codeowners_filter,omitempty"`
+	CodeownersTeams  []string `yaml:"codeowners_teams,omitempty"`
+
+	// Markdown used for empty states in the PR report (optional)
+	EmptyDescriptionText string `yaml:"empty_description_text,omitempty"`
+	NoPRsText            string `yaml:"no_prs_text,omitempty"`
+
+	// Shift every heading in prs.md down this many levels, e.g. to paste it under a title
+	// of your own, and the Markdown written between PRs. A nil separator means the
+	// default "---"; an empty one omits it. (optional)
+	HeadingOffset    int     `yaml:"heading_offset,omitempty"`
+	SectionSeparator *string `yaml:"section_separator,omitempty"`
+
+	// Description extractor names keyed by repository pattern, e.g. "myorg/*": first-heading (optional)
+	Extractors map[string]string `yaml:"extractors,omitempty"`
+
+	// text/template for each PR's block in prs.md, replacing the default layout (optional)
+	PRTemplate string `yaml:"pr_template,omitempty"`
+
+	// Tag each PR with one of ImpactTags using an extra summarizer pass (optional)
+	ClassifyPRs bool     `yaml:"classify_prs,omitempty"`
+	ImpactTags  []string `yaml:"impact_tags,omitempty"`
+
+	// Add a heuristic contribution score, weighting PRs by review depth, to report
+	// stats (optional). Weights not given in ScoreWeights keep their defaults.
+	ContributionScore bool               `yaml:"contribution_score,omitempty"`
+	ScoreWeights      map[string]float64 `yaml:"score_weights,omitempty"`
+
+	// Show each PR's lead time from creation to merge, and the average in the stats (optional)
+	ShowLeadTime bool `yaml:"show_lead_time,omitempty"`
+
+	// Note PR descriptions that read like generated boilerplate (optional). A description
+	// is flagged when it matches BoilerplateMinMatches (default 2) of the default patterns
+	// plus BoilerplatePatterns.
+	FlagBoilerplate       bool     `yaml:"flag_boilerplate,omitempty"`
+	BoilerplatePatterns   []string `yaml:"boilerplate_patterns,omitempty"`
+	BoilerplateMinMatches int      `yaml:"boilerplate_min_matches,omitempty"`
+
+	// Rows of each PR's metadata table in prs.md, in order (optional; see metadataFields)
+	MetadataFields []string `yaml:"metadata_fields,omitempty"`
+
+	// Group chains of stacked PRs under a shared heading in prs.md (optional)
+	GroupStackedPRs bool `yaml:"group_stacked_prs,omitempty"`
+
+	// Order of repository sections in reports: as-configured (default), alpha, or volume
+	RepoOrder string `yaml:"repo_order,omitempty"`
+	// Also list configured repos without any PRs, each with a short note (optional)
+	ShowEmptyRepos bool `yaml:"show_empty_repos,omitempty"`
+	// Repos with fewer PRs than this share one Miscellaneous section (optional)
+	MinPRsPerRepoSection int `yaml:"min_prs_per_repo_section,omitempty"`
+	// Friendly names shown for repos in reports, keyed by "owner/name" (optional)
+	RepoDisplayNames map[string]string `yaml:"repo_display_names,omitempty"`
+
+	// Report formats to write: any of markdown (default), json, html, pdf, and docx.
+	// prs.md and prs.json are always written, because the summarizer and -diff-against
+	// read them. pdf and docx are converted from the Markdown by pandoc. OutputFormat is
+	// the older single-format spelling.
+	OutputFormats []string `yaml:"output_formats,omitempty"`
+	OutputFormat  string   `yaml:"output_format,omitempty"`
+	// Also convert prs.md (and team-report.md) to pdf/docx, not just the summary (optional)
+	DocumentIncludePRs bool `yaml:"document_include_prs,omitempty"`
+	// Path to pandoc, for pdf and docx output (default: pandoc on the PATH)
+	PandocPath string `yaml:"pandoc_path,omitempty"`
+
+	// Summarizer backend: copilot (default), chat (OpenAI-compatible chat completions API), or echo (for testing)
+	Summarizer    string `yaml:"summarizer,omitempty"`
+	SystemPrompt  string `yaml:"system_prompt,omitempty"`
+	ChatURL       string `yaml:"chat_url,omitempty"`
+	ChatModel     string `yaml:"chat_model,omitempty"`
+	ChatAPIKeyEnv string `yaml:"chat_api_key_env,omitempty"`
+
+	// Most summarizer calls to run at once (default 1). In manager mode, users are
+	// summarized this many at a time.
+	SummarizerConcurrency int `yaml:"summarizer_concurrency,omitempty"`
+
+	// Summarize inputs longer than this many characters in chunks, caching each chunk's
+	// summary, and then summarize the chunk summaries (optional, 0 = never)
+	SummaryChunkChars int `yaml:"summary_chunk_chars,omitempty"`
+
+	// Fixed text around the generated summary (optional). Paths are relative to the config
+	// file. A nil title means the default "PR Summary"; an empty one omits the heading.
+	SummaryPrefixFile string  `yaml:"summary_prefix_file,omitempty"`
+	SummarySuffixFile string  `yaml:"summary_suffix_file,omitempty"`
+	SummaryTitle      *string `yaml:"summary_title,omitempty"`
+
+	// Also write brag.md, a flat list of one-line accomplishments with PR links, using
+	// BragPrompt in place of the default brag doc prompt if set (optional)
+	GenerateBragDoc bool   `yaml:"generate_brag_doc,omitempty"`
+	BragPrompt      string `yaml:"brag_prompt,omitempty"`
+
+	// Truncate rendered descriptions to this many characters (optional, 0 = no limit)
+	MaxDescriptionChars int `yaml:"max_description_chars,omitempty"`
+
+	// Replace text in descriptions that looks like credentials with [REDACTED] before
+	// it is written or summarized (optional). RedactPatterns are regular expressions
+	// redacted too, with or without RedactDescriptions.
+	RedactDescriptions bool     `yaml:"redact_descriptions,omitempty"`
+	RedactPatterns     []string `yaml:"redact_patterns,omitempty"`
+
+	// Also list the user's still-open PRs in a separate "In Progress" section (optional)
+	IncludeOpenPRs bool `yaml:"include_open_prs,omitempty"`
+
+	// Read each PR's timeline to flag PRs that were later reverted (optional, extra API calls)
+	IncludeTimeline bool `yaml:"include_timeline,omitempty"`
+
+	// Summarize the check runs on each PR's merge commit (optional, extra API calls)
+	IncludeChecks bool `yaml:"include_checks,omitempty"`
+
+	// Also include PRs by others where the user is a Co-authored-by trailer (optional)
+	IncludeCoAuthored bool     `yaml:"include_co_authored,omitempty"`
+	CoAuthorEmails    []string `yaml:"co_author_emails,omitempty"`
+	// Only match Co-authored-by trailers with emails under these domains (optional)
+	IdentityDomains []string `yaml:"identity_domains,omitempty"`
+
+	// Describe each repository at the top of prs.md, from its GitHub description and
+	// topics, so the summarizer knows what it does (optional)
+	IncludeRepoContext bool `yaml:"include_repo_context,omitempty"`
+
+	// Warn (or fail with -strict) when fewer PRs than this are found
+	MinExpectedPRs int `yaml:"min_expected_prs,omitempty"`
+
+	// Cache fetched PR details on disk (optional). Details of PRs merged in the last
+	// CacheFreshnessDays days (default 7) are always fetched again, since they may change.
+	CachePRs           bool `yaml:"cache_prs,omitempty"`
+	CacheFreshnessDays *int `yaml:"cache_freshness_days,omitempty"`
+
 	// Parsed fields (not in YAML)
-	SinceTime time.Time `yaml:"-"`
-	UntilTime time.Time `yaml:"-"`
-	ReposNWO  []NWO     `yaml:"-"`
+	SinceTime        time.Time          `yaml:"-"`
+	UntilTime        time.Time          `yaml:"-"`
+	ReposNWO         []NWO              `yaml:"-"`
+	ReleaseRepoNWO   NWO                `yaml:"-"`
+	BusinessLocation *time.Location     `yaml:"-"`
+	ExtractorRules   []extractorRule    `yaml:"-"`
+	RedactRules      []*regexp.Regexp   `yaml:"-"`
+	Metadata         []metadataField    `yaml:"-"`
+	BoilerplateRules []*regexp.Regexp   `yaml:"-"`
+	Weights          scoreWeights       `yaml:"-"`
+	PRTemplateParsed *template.Template `yaml:"-"`
+	SummaryPrefix    string             `yaml:"-"`
+	SummarySuffix    string             `yaml:"-"`
+
+	// Command line settings (not in YAML)
+	Strict      bool   `yaml:"-"`
+	DiffAgainst string `yaml:"-"`
+	Explain     bool   `yaml:"-"`
+	EmitICal    bool   `yaml:"-"`
+	// Write extraction-debug.md listing PRs whose extractor fell back (-debug-extraction)
+	DebugExtraction bool `yaml:"-"`
+	// Fail if the summarizer creates, modifies, or removes files
+	StrictSummarizer bool `yaml:"-"`
+	// Print just the summary to stdout instead of writing summary.md
+	SummaryToStdout bool `yaml:"-"`
+	// Summarize every chunk again instead of using cached chunk summaries (-refresh-chunks)
+	RefreshChunks bool `yaml:"-"`
+
+	// Neither username nor usernames is set, so the token's user is the author
+	UsernameFromToken bool `yaml:"-"`
+
+	// Listing the repos the users contributed to, so repos isn't needed
+	DiscoverRepos bool `yaml:"-"`
 }
 
 type NWO struct {
@@ -55,81 +246,461 @@ type NWO struct {
 
 // Parse validates and parses the configuration
 func (c *Config) Parse() error {
-	// Validate required fields
-	if c.Username == "" {
-		return fmt.Errorf("username is required")
+	// Validate required fields. Without a username, the GitHub token's user is looked up
+	// at run time.
+	c.UsernameFromToken = c.Username == "" && len(c.Usernames) == 0
+	if c.Username != "" && len(c.Usernames) > 0 {
+		return fmt.Errorf("username and usernames cannot both be set")
 	}
 	if c.OutputDir == "" {
 		return fmt.Errorf("output_dir is required")
 	}
-	if len(c.Repos) == 0 {
+	if len(c.Repos) == 0 && !c.DiscoverRepos {
 		return fmt.Errorf("repos list cannot be empty")
 	}
 
-	// Set default days if not specified
-	if c.Days == 0 && c.Since == "" && c.Until == "" {
-		c.Days = defaultDays
-	}
-
 	// Parse repositories
 	var repos []NWO
 	for _, repoStr := range c.Repos {
-		parts := strings.Split(strings.TrimSpace(repoStr), "/")
-		if len(parts) != 2 {
-			return fmt.Errorf("invalid repository format '%s': expected 'owner/name'", repoStr)
+		repo, err := parseNWO(repoStr)
+		if err != nil {
+			return err
 		}
+		repos = append(repos, repo)
+	}
+	c.ReposNWO = repos
 
-		owner := strings.TrimSpace(parts[0])
-		name := strings.TrimSpace(parts[1])
-
-		if owner == "" || name == "" {
-			return fmt.Errorf("invalid repository format '%s': owner and name cannot be empty", repoStr)
+	// Normalize users so a single username is just a team of one
+	if c.Username != "" {
+		c.Usernames = []string{c.Username}
+	}
+	for i, username := range c.Usernames {
+		c.Usernames[i] = strings.TrimSpace(username)
+		if c.Usernames[i] == "" {
+			return fmt.Errorf("usernames cannot contain empty entries")
 		}
+	}
+	if c.CombineUsers && len(c.Usernames) < 2 {
+		return fmt.Errorf("combine_users requires at least two usernames")
+	}
 
-		repos = append(repos, NWO{
-			Owner: owner,
-			Name:  name,
-		})
+	// Apply defaults for report text
+	if c.EmptyDescriptionText == "" {
+		c.EmptyDescriptionText = defaultEmptyDescriptionText
+	}
+	if c.NoPRsText == "" {
+		c.NoPRsText = defaultNoPRsText
+	}
+
+	if c.HeadingOffset < 0 {
+		return fmt.Errorf("heading_offset cannot be negative")
+	}
+	if c.HeadingOffset > maxHeadingLevel-1 {
+		return fmt.Errorf("heading_offset cannot be more than %d, since Markdown only has %d heading levels", maxHeadingLevel-1, maxHeadingLevel)
+	}
+
+	if c.MaxDescriptionChars < 0 {
+		return fmt.Errorf("max_description_chars cannot be negative")
+	}
+	if c.MinExpectedPRs < 0 {
+		return fmt.Errorf("min_expected_prs cannot be negative")
+	}
+	if c.TeamSummary && !c.CombineUsers {
+		return fmt.Errorf("team_summary requires combine_users")
+	}
+
+	// Parse release tag settings
+	var err error
+	if c.usesReleaseTags() {
+		if c.ReleaseRepo == "" {
+			return fmt.Errorf("release_repo is required when since_tag or until_tag is set")
+		}
+		c.ReleaseRepoNWO, err = parseNWO(c.ReleaseRepo)
+		if err != nil {
+			return fmt.Errorf("invalid release_repo: %w", err)
+		}
+		if c.SinceTag != "" && c.Since != "" {
+			return fmt.Errorf("since and since_tag cannot both be set")
+		}
+		if c.UntilTag != "" && c.Until != "" {
+			return fmt.Errorf("until and until_tag cannot both be set")
+		}
+		if c.SinceTag == "" && c.Since == "" {
+			return fmt.Errorf("since or since_tag is required when until_tag is set")
+		}
+	} else if c.ReleaseRepo != "" {
+		return fmt.Errorf("release_repo requires since_tag or until_tag")
 	}
-	c.ReposNWO = repos
 
 	// Parse dates
+	if err := c.parseDateRange(time.Now()); err != nil {
+		return err
+	}
+
+	// Parse business hours settings
+	c.BusinessLocation = time.UTC
+	if c.BusinessTimezone != "" {
+		c.BusinessLocation, err = time.LoadLocation(c.BusinessTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid business_timezone '%s': %w", c.BusinessTimezone, err)
+		}
+	}
+	switch c.UnknownMergeTime {
+	case "":
+		c.UnknownMergeTime = unknownMergeTimeSkip
+	case unknownMergeTimeSkip, unknownMergeTimeInclude:
+	default:
+		return fmt.Errorf("invalid unknown_merge_time '%s': expected '%s' or '%s'", c.UnknownMergeTime, unknownMergeTimeSkip, unknownMergeTimeInclude)
+	}
+	for i, association := range c.AuthorAssociations {
+		association = strings.ToUpper(strings.TrimSpace(association))
+		if !slices.Contains(authorAssociations, association) {
+			return fmt.Errorf("invalid author association '%s': expected one of %s", c.AuthorAssociations[i], strings.Join(authorAssociations, ", "))
+		}
+		c.AuthorAssociations[i] = association
+	}
+	if len(c.CodeownersTeams) > 0 && !c.CodeownersFilter {
+		return fmt.Errorf("codeowners_teams requires codeowners_filter")
+	}
+	for _, team := range c.CodeownersTeams {
+		org, name, ok := strings.Cut(strings.TrimPrefix(team, "@"), "/")
+		if !ok || org == "" || name == "" {
+			return fmt.Errorf("invalid codeowners team '%s': expected org/team", team)
+		}
+	}
+
+	// Parse repository order
+	switch c.RepoOrder {
+	case "":
+		c.RepoOrder = repoOrderAsConfigured
+	case repoOrderAsConfigured, repoOrderAlpha, repoOrderVolume:
+	default:
+		return fmt.Errorf("invalid repo_order '%s': expected '%s', '%s', or '%s'", c.RepoOrder, repoOrderAsConfigured, repoOrderAlpha, repoOrderVolume)
+	}
+	if c.MinPRsPerRepoSection < 0 {
+		return fmt.Errorf("min_prs_per_repo_section cannot be negative")
+	}
+	for repo, name := range c.RepoDisplayNames {
+		if _, err := parseNWO(repo); err != nil {
+			return fmt.Errorf("invalid repo_display_names entry: %w", err)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("repo_display_names entry for '%s' is empty", repo)
+		}
+	}
+
+	// Parse output formats
+	if c.OutputFormat != "" {
+		if len(c.OutputFormats) > 0 {
+			return fmt.Errorf("output_format and output_formats cannot both be set; list every format in output_formats")
+		}
+		c.OutputFormats = []string{c.OutputFormat}
+	}
+	if len(c.OutputFormats) == 0 {
+		c.OutputFormats = []string{outputFormatMarkdown}
+	}
+	seenFormats := make(map[string]bool)
+	for i, format := range c.OutputFormats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !slices.Contains(outputFormats, format) {
+			return fmt.Errorf("invalid output format '%s': expected one of %s", c.OutputFormats[i], strings.Join(outputFormats, ", "))
+		}
+		if seenFormats[format] {
+			return fmt.Errorf("duplicate output format '%s'", format)
+		}
+		seenFormats[format] = true
+		c.OutputFormats[i] = format
+	}
+	if c.DocumentIncludePRs && len(c.documentFormats()) == 0 {
+		return fmt.Errorf("document_include_prs requires output format '%s' or '%s'", outputFormatPDF, outputFormatDOCX)
+	}
+	if c.PandocPath == "" {
+		c.PandocPath = defaultPandocPath
+	}
+
+	// Parse PR cache settings
+	if c.CacheFreshnessDays == nil {
+		days := defaultCacheFreshnessDays
+		c.CacheFreshnessDays = &days
+	}
+	if *c.CacheFreshnessDays < 0 {
+		return fmt.Errorf("cache_freshness_days cannot be negative")
+	}
+
+	// Parse summarizer settings
+	if c.SummarizerConcurrency < 0 {
+		return fmt.Errorf("summarizer_concurrency cannot be negative")
+	}
+	if c.SummarizerConcurrency == 0 {
+		c.SummarizerConcurrency = 1
+	}
+	if c.SummaryChunkChars < 0 {
+		return fmt.Errorf("summary_chunk_chars cannot be negative")
+	}
+	switch c.Summarizer {
+	case "":
+		c.Summarizer = summarizerCopilot
+	case summarizerCopilot, summarizerEcho:
+	case summarizerChat:
+		if c.ChatModel == "" {
+			return fmt.Errorf("chat_model is required when summarizer is '%s'", summarizerChat)
+		}
+		if c.ChatURL == "" {
+			c.ChatURL = defaultChatURL
+		}
+	default:
+		return fmt.Errorf("invalid summarizer '%s': expected '%s', '%s', or '%s'", c.Summarizer, summarizerCopilot, summarizerChat, summarizerEcho)
+	}
+
+	// Read summary prefix and suffix
+	if c.SummaryPrefixFile != "" {
+		data, err := os.ReadFile(c.SummaryPrefixFile)
+		if err != nil {
+			return fmt.Errorf("failed to read summary_prefix_file: %w", err)
+		}
+		c.SummaryPrefix = string(data)
+	}
+	if c.SummarySuffixFile != "" {
+		data, err := os.ReadFile(c.SummarySuffixFile)
+		if err != nil {
+			return fmt.Errorf("failed to read summary_suffix_file: %w", err)
+		}
+		c.SummarySuffix = string(data)
+	}
+
+	// Parse impact tags
+	if len(c.ImpactTags) == 0 {
+		c.ImpactTags = slices.Clone(defaultImpactTags)
+	}
+	seenTags := make(map[string]bool)
+	for i, tag := range c.ImpactTags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.ContainsAny(tag, " \t") {
+			return fmt.Errorf("invalid impact tag '%s': tags must be single words", c.ImpactTags[i])
+		}
+		if seenTags[tag] {
+			return fmt.Errorf("duplicate impact tag '%s'", tag)
+		}
+		seenTags[tag] = true
+		c.ImpactTags[i] = tag
+	}
+
+	// Parse identity domains, accepting "mycorp.com" or "@mycorp.com"
+	for i, domain := range c.IdentityDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain == "" || strings.ContainsAny(domain, "@ \t") {
+			return fmt.Errorf("invalid identity domain '%s': expected a domain like 'mycorp.com'", c.IdentityDomains[i])
+		}
+		c.IdentityDomains[i] = domain
+	}
+
+	// Parse contribution score weights
+	if len(c.ScoreWeights) > 0 && !c.ContributionScore {
+		return fmt.Errorf("score_weights requires contribution_score")
+	}
+	c.Weights, err = parseScoreWeights(c.ScoreWeights)
+	if err != nil {
+		return err
+	}
+
+	// Parse per-PR template
+	if c.PRTemplate != "" {
+		c.PRTemplateParsed, err = parsePRTemplate(c.PRTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Parse description extractors
+	c.ExtractorRules, err = buildExtractorRules(c.Extractors)
+	if err != nil {
+		return err
+	}
+
+	// Parse redaction rules
+	c.RedactRules, err = compileRedactRules(c.RedactPatterns, c.RedactDescriptions)
+	if err != nil {
+		return err
+	}
+
+	// Parse boilerplate patterns
+	if c.BoilerplateMinMatches < 0 {
+		return fmt.Errorf("boilerplate_min_matches cannot be negative")
+	}
+	if c.BoilerplateMinMatches == 0 {
+		c.BoilerplateMinMatches = defaultBoilerplateMinMatches
+	}
+	if c.FlagBoilerplate {
+		c.BoilerplateRules, err = compileBoilerplatePatterns(c.BoilerplatePatterns)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Parse metadata table rows
+	c.Metadata, err = parseMetadataFields(c.MetadataFields, *c)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// parseDateRange sets SinceTime and UntilTime. The precedence is:
+//
+//   - since and until: exactly that range
+//   - since only: since until now
+//   - until only: days (default 30) before until, up to until
+//   - neither: days (default 30) before now, up to now
+//
+// days can only be set when since is not, since it is measured back from the end of the
+// range. With since_tag or until_tag, the tags are resolved to dates later by
+// resolveReleaseTags, once a GitHub client is available.
+func (c *Config) parseDateRange(now time.Time) error {
+	if c.Days < 0 {
+		return fmt.Errorf("days cannot be negative")
+	}
+	if c.Days != 0 && (c.Since != "" || c.SinceTag != "") {
+		return fmt.Errorf("days cannot be combined with since or since_tag: the range already starts there")
+	}
+
 	var err error
-	if c.Since != "" && c.Until != "" {
+	if c.Since != "" {
 		c.SinceTime, err = time.Parse(dateFormat, c.Since)
 		if err != nil {
 			return fmt.Errorf("invalid since date format '%s': %w", c.Since, err)
 		}
+	}
+	if c.Until != "" {
 		c.UntilTime, err = time.Parse(dateFormat, c.Until)
 		if err != nil {
 			return fmt.Errorf("invalid until date format '%s': %w", c.Until, err)
 		}
-	} else {
-		// Use days parameter
-		c.UntilTime = time.Now()
+		if c.usesReleaseTags() {
+			// Release windows are compared to exact merge times, and an until date
+			// includes the whole of that day, as it does for a plain date range
+			c.UntilTime = c.UntilTime.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	} else if c.UntilTag == "" {
+		c.UntilTime = now
+	}
+
+	if c.usesReleaseTags() {
+		return nil
+	}
+
+	if c.Since == "" {
+		if c.Days == 0 {
+			c.Days = defaultDays
+		}
 		c.SinceTime = c.UntilTime.AddDate(0, 0, -c.Days)
 	}
+	if c.SinceTime.After(c.UntilTime) {
+		return fmt.Errorf("since (%s) is after until (%s)", c.SinceTime.Format(dateFormat), c.UntilTime.Format(dateFormat))
+	}
+	return nil
+}
+
+// resolvePaths expands "~" in the paths in the config and makes relative ones into
+// paths under baseDir, the directory of the config file
+func (c *Config) resolvePaths(baseDir string) error {
+	paths := []*string{&c.OutputDir, &c.SummaryPrefixFile, &c.SummarySuffixFile}
+	// A bare command name is looked up on the PATH instead
+	if strings.ContainsRune(c.PandocPath, filepath.Separator) || strings.HasPrefix(c.PandocPath, "~") {
+		paths = append(paths, &c.PandocPath)
+	}
 
+	for _, path := range paths {
+		resolved, err := resolvePath(baseDir, *path)
+		if err != nil {
+			return err
+		}
+		*path = resolved
+	}
 	return nil
 }
 
+// wantsFormat reports whether format is one of the requested output formats
+func (c *Config) wantsFormat(format string) bool {
+	return slices.Contains(c.OutputFormats, format)
+}
+
+// usesReleaseTags reports whether the date window is anchored to release tags
+func (c *Config) usesReleaseTags() bool {
+	return c.SinceTag != "" || c.UntilTag != ""
+}
+
+// parseNWO parses an "owner/name" repository reference
+func parseNWO(repoStr string) (NWO, error) {
+	parts := strings.Split(strings.TrimSpace(repoStr), "/")
+	if len(parts) != 2 {
+		return NWO{}, fmt.Errorf("invalid repository format '%s': expected 'owner/name'", repoStr)
+	}
+
+	owner := strings.TrimSpace(parts[0])
+	name := strings.TrimSpace(parts[1])
+
+	if owner == "" || name == "" {
+		return NWO{}, fmt.Errorf("invalid repository format '%s': owner and name cannot be empty", repoStr)
+	}
+
+	return NWO{Owner: owner, Name: name}, nil
+}
+
 // PullRequestInfo holds the information we want to display about PRs
 type PullRequestInfo struct {
-	Repository  string
-	Title       string
-	Description string
-	URL         string
-	CreatedAt   time.Time
-	MergedAt    *time.Time
+	Author      string     `json:"author,omitempty"`
+	Repository  string     `json:"repository"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	URL         string     `json:"url"`
+	Number      int        `json:"number,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	MergedAt    *time.Time `json:"merged_at,omitempty"`
+	CoAuthored  bool       `json:"co_authored,omitempty"`
+	RevertedBy  string     `json:"reverted_by,omitempty"`
+	Open        bool       `json:"open,omitempty"`
+	ImpactTag   string     `json:"impact_tag,omitempty"`
+
+	// The PR author's association with the repository, e.g. MEMBER
+	AuthorAssociation string `json:"author_association,omitempty"`
+
+	// Discussion on the PR, for the contribution score
+	Comments       int `json:"comments,omitempty"`
+	ReviewComments int `json:"review_comments,omitempty"`
+
+	// The branch the PR merges into, the branch it merges from, and the repository's
+	// default branch, for finding stacked PRs
+	BaseRef       string `json:"base_ref,omitempty"`
+	HeadRef       string `json:"head_ref,omitempty"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+
+	// The commit the PR was merged as, and the outcome of its check runs with include_checks
+	MergeCommitSHA string        `json:"merge_commit_sha,omitempty"`
+	Checks         *checkSummary `json:"checks,omitempty"`
+
+	// Position in a chain of stacked PRs, e.g. "2 of 3", set while rendering
+	Stack string `json:"-"`
 }
 
-// loadConfig loads configuration from a YAML file
-func loadConfig(configPath string) (*Config, error) {
+// loadConfig loads configuration from a YAML file. With discoverRepos, repos may be left
+// out because the run will look them up instead.
+func loadConfig(configPath string, discoverRepos bool) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
 
+	// Check the document against the config schema first so that every problem
+	// is reported with its field name and line, not just the first one
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	if problems := validateConfigDocument(&doc, requiredConfigFields); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid config file %s:\n  %s", configPath, strings.Join(problems, "\n  "))
+	}
+
 	var config Config
 
 	// Create a decoder with strict mode to reject unknown fields
@@ -140,6 +711,13 @@ func loadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
+	config.DiscoverRepos = discoverRepos
+
+	// File paths in the config are relative to the config file, not the working directory
+	if err := config.resolvePaths(filepath.Dir(configPath)); err != nil {
+		return nil, err
+	}
+
 	// Parse and validate the configuration
 	if err := config.Parse(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -159,8 +737,8 @@ func confirmOverwrite(filePath string) (bool, error) {
 		return false, fmt.Errorf("error checking file %s: %w", filePath, err)
 	}
 
-	// File exists, ask for confirmation
-	fmt.Printf("File %s already exists. Do you want to overwrite it? (y/N): ", filePath)
+	// File exists, ask for confirmation on stderr so that stdout only carries output
+	fmt.Fprintf(os.Stderr, "File %s already exists. Do you want to overwrite it? (y/N): ", filePath)
 	var response string
 	fmt.Scanln(&response)
 
@@ -174,122 +752,604 @@ func confirmOverwrite(filePath string) (bool, error) {
 }
 
 func main() {
+	if err := run(); err != nil {
+		console.Errorf("%v", err)
+		os.Exit(exitCodeFor(err))
+	}
+}
+
+// run does the work of main, returning an error whose exit code is given by exitCodeFor
+func run() error {
+	if len(os.Args) > 1 && os.Args[1] == "rollup" {
+		return runRollup(os.Args[2:])
+	}
+
 	// Parse command line arguments
 	var (
-		configFile = flag.String("config", "config.yaml", "Path to configuration file")
+		configFile  = flag.String("config", "config.yaml", "Path to configuration file")
+		printSchema = flag.Bool("print-schema", false, "Print a JSON Schema for the configuration file and exit")
+		strict      = flag.Bool("strict", false, "Exit with an error instead of warning when fewer than min_expected_prs PRs are found")
+		colorMode   = flag.String("color", colorAuto, "Whether to use color in terminal output: auto, always, or never")
+		diffAgainst = flag.String("diff-against", "", "Path to a prs.json from a previous run; only PRs not in it are written to prs.md")
+		explain     = flag.Bool("explain", false, "Write decisions.md explaining why each candidate PR was or wasn't included")
+		tokenCache  = flag.Duration("token-cache-ttl", 0, "Cache the gh token on disk for this long, e.g. 10m (default: no disk cache)")
+		emitICal    = flag.Bool("emit-ical", false, "Also write prs.ics, a calendar with each merged PR as an event at its merge time")
+		tempOutput  = flag.Bool("temp-output", false, "Write all output to a new temporary directory instead of output_dir, and print its path")
+		cleanup     = flag.Bool("cleanup", false, "With -temp-output, remove the temporary directory when the run ends, even if it fails or is interrupted")
+		safePaths   = flag.Bool("safe-paths", false, "Refuse to write reports outside the config file's directory, following symlinks")
+		maxAgeCache = flag.Int("max-age-cache", -1, "Fetch PRs merged in the last this many days again even if cached; overrides cache_freshness_days")
+		listRepos   = flag.Bool("list-repos-contributed", false, "List the repositories the configured users merged PRs into during the date range, then exit; repos may be left out of the config")
+		writeRepos  = flag.Bool("write-repos", false, "With -list-repos-contributed, also write the repositories found into the config file's repos list")
+		summaryOnly = flag.Bool("summary-only", false, "Regenerate summary.md from the existing prs.md without contacting GitHub, overwriting it without asking")
+		strictSumm  = flag.Bool("strict-summarizer", false, "Fail if the summarizer creates, modifies, or removes files in the output directory or next to its input")
+		toStdout    = flag.Bool("summary-to-stdout", false, "Print only the generated summary to stdout, without a title, instead of writing summary.md; logs stay on stderr")
+		debugExtr   = flag.Bool("debug-extraction", false, "Write extraction-debug.md listing PRs whose extractor found none of its headings and used the whole description")
+		debugSearch = flag.String("debug-search", "", "Dump the raw GitHub search results for each query to this file, or to stderr for -")
+		refreshChnk = flag.Bool("refresh-chunks", false, "With summary_chunk_chars, summarize every chunk again instead of reusing chunk summaries cached by earlier runs")
 	)
 	flag.Parse()
 
+	useColor, err := resolveColor(*colorMode, stderrIsTerminal(), os.Getenv("NO_COLOR") != "")
+	if err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	console.color = useColor
+
+	if *printSchema {
+		schema, err := marshalConfigSchema()
+		if err != nil {
+			return fmt.Errorf("failed to generate config schema: %w", err)
+		}
+		fmt.Println(schema)
+		return nil
+	}
+
 	// Load configuration from file
-	config, err := loadConfig(*configFile)
+	config, err := loadConfig(*configFile, *listRepos)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	if *cleanup && !*tempOutput {
+		return withExitCode(exitConfig, errors.New("-cleanup only applies with -temp-output"))
+	}
+	if *tempOutput {
+		dir, remove, err := makeTempOutputDir(*cleanup)
+		if err != nil {
+			return err
+		}
+		defer remove()
+		config.OutputDir = dir
+		console.Infof("Writing output to temporary directory %s", dir)
+	} else if *safePaths {
+		// A temporary directory is outside the config directory by design
+		if err := checkSafeOutputDir(config.OutputDir, filepath.Dir(*configFile)); err != nil {
+			return withExitCode(exitConfig, err)
+		}
+	}
+	config.Strict = *strict
+	config.DiffAgainst = *diffAgainst
+	config.Explain = *explain
+	config.StrictSummarizer = *strictSumm
+	config.DebugExtraction = *debugExtr
+	config.SummaryToStdout = *toStdout
+	if config.SummaryToStdout && len(config.Usernames) > 1 {
+		return withExitCode(exitConfig, errors.New("-summary-to-stdout needs a single username, since only one summary can go to stdout"))
+	}
+	config.EmitICal = *emitICal
+	config.RefreshChunks = *refreshChnk
+	if config.RefreshChunks && config.SummaryChunkChars == 0 {
+		console.Warnf("-refresh-chunks has no effect without summary_chunk_chars")
+	}
+	if *maxAgeCache >= 0 {
+		if !config.CachePRs {
+			console.Warnf("-max-age-cache has no effect without cache_prs")
+		}
+		config.CacheFreshnessDays = maxAgeCache
+	}
+	if err := checkDocumentConverter(*config); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	ctx := context.Background()
+	summarizer, err := newSummarizer(*config)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		return withExitCode(exitSummarizer, fmt.Errorf("failed to set up summarizer: %w", err))
+	}
+	svc := newServices(ctx, summarizer, newTokenSource(*tokenCache))
+	if config.CachePRs {
+		cache, err := newPRCache(githubHost(), time.Duration(*config.CacheFreshnessDays)*24*time.Hour)
+		if err != nil {
+			console.Warnf("Not caching PR details: %v", err)
+		} else {
+			svc.prCache = cache
+		}
+	}
+	if *debugSearch != "" {
+		output, closeOutput, err := openDebugSearchOutput(*debugSearch)
+		if err != nil {
+			return withExitCode(exitConfig, err)
+		}
+		defer closeOutput()
+		svc.debugSearch = output
+	}
+
+	if *summaryOnly {
+		if *listRepos {
+			return withExitCode(exitConfig, errors.New("-summary-only cannot be combined with -list-repos-contributed"))
+		}
+		return runSummaryOnly(ctx, *config, svc)
+	}
+
+	if config.UsernameFromToken {
+		client, err := svc.githubClient()
+		if err != nil {
+			return err
+		}
+		username, err := usernameFromToken(ctx, client)
+		if err != nil {
+			return withExitCode(exitAuth, err)
+		}
+		console.Infof("No username configured; using %s, the owner of the GitHub token", username)
+		config.Username = username
+		config.Usernames = []string{username}
+	}
+
+	if config.usesReleaseTags() {
+		client, err := svc.githubClient()
+		if err != nil {
+			return err
+		}
+		if err := resolveReleaseTags(ctx, client, config); err != nil {
+			return fmt.Errorf("failed to resolve release tags: %w", err)
+		}
+		console.Infof("Using releases of %s: %s to %s", config.ReleaseRepo,
+			config.SinceTime.Format(time.RFC3339), config.UntilTime.Format(time.RFC3339))
+	}
+
+	if *listRepos {
+		return listReposContributed(ctx, svc, *config, *configFile, *writeRepos)
+	}
+
+	if config.IncludeRepoContext {
+		client, err := svc.githubClient()
+		if err != nil {
+			return err
+		}
+		svc.repoContexts = fetchRepoContexts(ctx, client, config.ReposNWO)
+	}
+
+	// In manager mode (several usernames) each user gets their own subdirectory
+	multiUser := len(config.Usernames) > 1
+	var reports []userReport
+	var toSummarize []Config
+	for _, username := range config.Usernames {
+		userConfig := *config
+		userConfig.Username = username
+		if multiUser {
+			userConfig.OutputDir = filepath.Join(config.OutputDir, username)
+			console.Infof("Processing user %s", username)
+		}
+
+		report, err := fetchForUser(ctx, userConfig, svc)
+		if err != nil {
+			return fmt.Errorf("failed to process user %s: %w", username, err)
+		}
+		reports = append(reports, report)
+		if report.summarize {
+			toSummarize = append(toSummarize, userConfig)
+		}
+	}
+
+	// Users are summarized in parallel once all of them are fetched
+	if err := summarizeUsers(ctx, svc, toSummarize, summarizerWorkers(*config)); err != nil {
+		return err
+	}
+
+	if config.CombineUsers {
+		teamFile := filepath.Join(config.OutputDir, "team-report.md")
+		shouldWriteTeam, err := confirmOverwrite(teamFile)
+		if err != nil {
+			return fmt.Errorf("cannot check team report file: %w", err)
+		}
+		if !shouldWriteTeam {
+			console.Infof("Team report %s already exists and user chose not to overwrite.", teamFile)
+			return nil
+		}
+
+		if err := outputTeamReport(reports, svc.repoContexts, teamFile, *config); err != nil {
+			return fmt.Errorf("failed to write team report: %w", err)
+		}
+		if config.DocumentIncludePRs {
+			if err := writeDocuments(ctx, teamFile, *config); err != nil {
+				return fmt.Errorf("failed to convert team report: %w", err)
+			}
+		}
+		if config.wantsFormat(outputFormatHTML) {
+			for i := range reports {
+				if reports[i].Profile == nil {
+					reports[i].Profile = svc.profiles.Get(ctx, reports[i].Username)
+				}
+			}
+			if err := outputTeamReportHTML(reports, filepath.Join(config.OutputDir, "team-report.html"), *config); err != nil {
+				return fmt.Errorf("failed to write HTML team report: %w", err)
+			}
+		}
+
+		if config.TeamSummary {
+			console.Infof("Generating team summary with %s...", config.Summarizer)
+			if err := summarizeFile(ctx, svc, teamFile, filepath.Join(config.OutputDir, "team-summary.md"), teamPrompt, *config); err != nil {
+				return fmt.Errorf("failed to write team summary: %w", err)
+			}
+		}
 	}
 
+	return nil
+}
+
+// services holds the clients and run state shared by every user in a run
+type services struct {
+	summarizer Summarizer
+	profiles   *profileCache
+	codeowners *codeownersCache
+
+	// Disk cache of PR details (nil unless cache_prs is set)
+	prCache *prCache
+	// Where -debug-search writes raw search results (nil when not debugging)
+	debugSearch io.Writer
+	// Descriptions of the repos keyed by "owner/name", fetched once per run with
+	// include_repo_context
+	repoContexts map[string]repoContext
+
+	ctx    context.Context
+	tokens *tokenSource
+	client *github.Client
+}
+
+// newServices creates the shared services. The GitHub client is created lazily, once
+// some user actually needs PRs fetched.
+func newServices(ctx context.Context, summarizer Summarizer, tokens *tokenSource) *services {
+	svc := &services{ctx: ctx, summarizer: summarizer, tokens: tokens}
+	svc.profiles = newProfileCache(svc.githubClient)
+	svc.codeowners = newCodeownersCache(svc.githubClient)
+	return svc
+}
+
+// githubClient returns the GitHub client, creating it on first use
+func (s *services) githubClient() (*github.Client, error) {
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	client, err := newGitHubClient(s.tokens, githubHost(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.client = client
+	return s.client, nil
+}
+
+// userReport holds the PRs fetched for one user
+type userReport struct {
+	Username string
+	Profile  *userProfile
+	PRs      []PullRequestInfo
+	OpenPRs  []PullRequestInfo
+
+	// Whether the user's summary is to be (re)generated from prs.md
+	summarize bool
+}
+
+// fetchForUser asks about overwriting the user's files, fetches the PRs for
+// config.Username, and writes prs.md into config.OutputDir. Whether the summary should then
+// be written is recorded in the report; summarizeUsers writes it. The GitHub client is only
+// created if PRs actually need to be fetched.
+func fetchForUser(ctx context.Context, config Config, svc *services) (userReport, error) {
+	report := userReport{Username: config.Username}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
-		log.Fatalf("Failed to create output directory %s: %v", config.OutputDir, err)
+		return report, fmt.Errorf("failed to create output directory %s: %w", config.OutputDir, err)
+	}
+
+	// Load the previous snapshot up front, since it may be the very file this run overwrites
+	var previousPRs []PullRequestInfo
+	if config.DiffAgainst != "" {
+		var err error
+		previousPRs, err = loadPRsJSON(config.DiffAgainst)
+		if err != nil {
+			return report, err
+		}
 	}
 
 	// Check for existing output files and confirm overwrite BEFORE doing expensive work
 	prsFile := filepath.Join(config.OutputDir, "prs.md")
+	snapshotFile := filepath.Join(config.OutputDir, "prs.json")
 	summaryFile := filepath.Join(config.OutputDir, "summary.md")
 
-	// Check summary file first - if user doesn't want to generate new summary, exit early
-	shouldWriteSummary, err := confirmOverwrite(summaryFile)
-	if err != nil {
-		log.Fatalf("Cannot check summary file: %v", err)
+	// Check summary file first - if user doesn't want to generate new summary, exit early.
+	// A summary going to stdout doesn't touch the file.
+	shouldWriteSummary := true
+	if !config.SummaryToStdout {
+		var err error
+		shouldWriteSummary, err = confirmOverwrite(summaryFile)
+		if err != nil {
+			return report, fmt.Errorf("cannot check summary file: %w", err)
+		}
 	}
 
-	if !shouldWriteSummary {
-		log.Printf("Summary file %s already exists and user chose not to overwrite. Nothing to do.", summaryFile)
-		return
+	// The team report needs this user's PRs even if nothing is written for them
+	if !shouldWriteSummary && !config.CombineUsers {
+		console.Infof("Summary file %s already exists and user chose not to overwrite. Nothing to do.", summaryFile)
+		return report, nil
 	}
 
 	// Now check PR file since we know we'll need it for summary generation
 	shouldWritePRs, err := confirmOverwrite(prsFile)
 	if err != nil {
-		log.Fatalf("Cannot check PR file: %v", err)
+		return report, fmt.Errorf("cannot check PR file: %w", err)
 	}
 
-	// Only fetch PRs if we need to write the PR file
-	if shouldWritePRs {
-		// Get GitHub token using gh CLI
-		token, err := getGitHubToken()
+	// With -explain, every candidate PR's filter results are recorded
+	var decisions *decisionLog
+	if config.Explain {
+		decisions = newDecisionLog()
+	}
+
+	// Only fetch PRs if we need to write the PR file or build the team report
+	if shouldWritePRs || config.CombineUsers {
+		client, err := svc.githubClient()
 		if err != nil {
-			log.Fatalf("Failed to get GitHub token: %v", err)
+			return report, err
 		}
 
-		// Create GitHub client
-		ctx := context.Background()
-		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-		tc := oauth2.NewClient(ctx, ts)
-		client := github.NewClient(tc)
-
 		// Count total PRs across all repositories
-		log.Printf("Counting PRs across %d repositories...", len(config.ReposNWO))
+		console.Infof("Counting PRs across %d repositories...", len(config.ReposNWO))
 		totalPRs := 0
+		predicted := make(map[NWO]int)
 		for _, repo := range config.ReposNWO {
-			count, err := countMergedPRs(ctx, client, repo, *config)
+			count, err := countMergedPRs(ctx, client, repo, config)
 			if err != nil {
-				log.Printf("Warning: Error counting PRs from %s/%s: %v", repo.Owner, repo.Name, err)
+				console.Warnf("Failed to count PRs from %s/%s: %v", repo.Owner, repo.Name, err)
 				continue
 			}
+			predicted[repo] = count
 			totalPRs += count
 		}
 
+		// Retrieve PRs for each repository with progress tracking. Once GitHub rate
+		// limits us every later request would fail too, so stop fetching, write out
+		// what was fetched, and report the rate limit at the end. With no authored PRs,
+		// the passes below may still find co-authored and open PRs, and the report is
+		// written either way.
+		var allPRs []PullRequestInfo
+		var rateLimitErr error
 		if totalPRs == 0 {
-			log.Printf("No merged PRs found in the specified time range.")
-			return
+			console.Infof("No merged PRs found in the specified time range.")
+		} else {
+			console.Infof("Found %d PRs to process.", totalPRs)
+
+			// Create progress bar for individual PRs
+			bar := console.newProgressBar(totalPRs, "Processing PRs")
+			for _, repo := range config.ReposNWO {
+				prs, err := getMergedPRsWithProgress(ctx, svc, repo, config, bar)
+				if err != nil {
+					console.Errorf("Failed to fetch PRs from %s/%s (keeping %d fetched before the failure): %v", repo.Owner, repo.Name, len(prs), err)
+				} else {
+					reconcilePRCount(bar, repo, predicted[repo], len(prs))
+				}
+				allPRs = append(allPRs, prs...)
+				if isRateLimitError(err) {
+					rateLimitErr = err
+					break
+				}
+			}
+
+			bar.Finish()
+			console.Infof("Completed processing %d merged PRs", len(allPRs))
+		}
+
+		if config.IncludeCoAuthored && rateLimitErr == nil {
+			for _, repo := range config.ReposNWO {
+				prs, err := getCoAuthoredPRs(ctx, svc, repo, config)
+				if err != nil {
+					console.Errorf("Failed to fetch co-authored PRs from %s/%s (keeping %d fetched before the failure): %v", repo.Owner, repo.Name, len(prs), err)
+				}
+				allPRs = mergePRsByURL(allPRs, prs)
+				if isRateLimitError(err) {
+					rateLimitErr = err
+					break
+				}
+			}
 		}
 
-		log.Printf("Found %d PRs to process.", totalPRs)
+		// Applied even without filters, so that -explain lists every candidate
+		filters := buildFilters(config)
+		before := len(allPRs)
+		allPRs = applyFilters(allPRs, filters, decisions)
+		if len(filters) > 0 {
+			console.Infof("Kept %d of %d PRs after filtering", len(allPRs), before)
+		}
+		if config.CodeownersFilter && rateLimitErr == nil {
+			// Checked last, since it lists the files of every PR still kept
+			console.Infof("Listing files of %d PRs to check CODEOWNERS...", len(allPRs))
+			before := len(allPRs)
+			allPRs = applyFilters(allPRs, []prFilter{codeownersFilter(ctx, client, svc.codeowners, codeownersOwners(config))}, decisions)
+			console.Infof("Kept %d of %d PRs in areas owned by %s", len(allPRs), before, strings.Join(codeownersOwners(config), ", "))
+		}
+		if config.IncludeTimeline && rateLimitErr == nil {
+			console.Infof("Checking timelines of %d PRs for reverts...", len(allPRs))
+			annotateReverts(ctx, client, allPRs)
+		}
+		if config.IncludeChecks && rateLimitErr == nil {
+			console.Infof("Reading checks of %d PRs...", len(allPRs))
+			annotateChecks(ctx, client, allPRs)
+		}
+		report.PRs = allPRs
 
-		// Create progress bar for individual PRs
-		bar := progressbar.NewOptions(totalPRs,
-			progressbar.OptionSetDescription("Processing PRs"),
-			progressbar.OptionSetWriter(os.Stderr),
-			progressbar.OptionShowCount(),
-			progressbar.OptionShowIts(),
-			progressbar.OptionSetPredictTime(true),
-			progressbar.OptionFullWidth(),
-			progressbar.OptionSetRenderBlankState(true),
-		)
+		if config.IncludeOpenPRs && rateLimitErr == nil {
+			for _, repo := range config.ReposNWO {
+				prs, err := getOpenPRs(ctx, svc, repo, config)
+				if err != nil {
+					console.Errorf("Failed to fetch open PRs from %s/%s (keeping %d fetched before the failure): %v", repo.Owner, repo.Name, len(prs), err)
+				}
+				report.OpenPRs = append(report.OpenPRs, prs...)
+				if isRateLimitError(err) {
+					rateLimitErr = err
+					break
+				}
+			}
+			if len(config.AuthorAssociations) > 0 {
+				report.OpenPRs = applyFilters(report.OpenPRs, []prFilter{authorAssociationFilter(config.AuthorAssociations)}, nil)
+			}
+			console.Infof("Found %d open PRs in progress", len(report.OpenPRs))
+		}
 
-		// Retrieve PRs for each repository with progress tracking
-		var allPRs []PullRequestInfo
-		for _, repo := range config.ReposNWO {
-			prs, err := getMergedPRsWithProgress(ctx, client, repo, *config, bar)
-			if err != nil {
-				log.Printf("Error fetching PRs from %s/%s: %v", repo.Owner, repo.Name, err)
-				continue
+		if rateLimitErr == nil {
+			if err := checkMinExpectedPRs(len(allPRs), config); err != nil {
+				return report, err
 			}
-			allPRs = append(allPRs, prs...)
 		}
 
-		bar.Finish()
-		log.Printf("Completed processing %d merged PRs", len(allPRs))
+		if shouldWritePRs {
+			// The snapshot always holds everything fetched so the next run can diff against it
+			if err := writePRsJSON(allPRs, snapshotFile); err != nil {
+				return report, fmt.Errorf("error writing PR snapshot: %w", err)
+			}
+
+			reportPRs := allPRs
+			if config.DiffAgainst != "" {
+				reportPRs = applyFilters(allPRs, []prFilter{diffFilter(previousPRs, config.DiffAgainst)}, decisions)
+				console.Infof("%d of %d PRs are new since %s", len(reportPRs), len(allPRs), config.DiffAgainst)
+			}
+
+			if config.ClassifyPRs {
+				if err := classifyPRs(ctx, svc.summarizer, reportPRs, config); err != nil {
+					console.Warnf("Continuing without impact tags: %v", err)
+				}
+			}
+
+			// Write PR descriptions to the output directory
+			if err := outputPRFormats(ctx, svc, reportPRs, &report, prsFile, config); err != nil {
+				return report, err
+			}
 
-		// Write PR descriptions to the output directory
-		log.Printf("Writing PR descriptions to %s", prsFile)
-		if err := outputPRs(allPRs, prsFile); err != nil {
-			log.Fatalf("Error writing PR descriptions to output file: %v", err)
+			if config.DebugExtraction {
+				if err := outputExtractionDebug(reportPRs, config.ExtractorRules, filepath.Join(config.OutputDir, "extraction-debug.md")); err != nil {
+					return report, fmt.Errorf("error writing extraction debugging: %w", err)
+				}
+			}
 		}
-	} else {
-		log.Printf("Using existing PR descriptions from %s", prsFile)
+
+		if err := writeDecisions(decisions, config.OutputDir); err != nil {
+			return report, err
+		}
+
+		// A summary of an incomplete set of PRs would be misleading
+		if rateLimitErr != nil {
+			return report, fmt.Errorf("stopped fetching PRs after hitting the GitHub rate limit: %w", rateLimitErr)
+		}
+
+		if len(allPRs) == 0 && len(report.OpenPRs) == 0 {
+			console.Infof("No PRs found for %s, so there is nothing to summarize.", config.Username)
+			return report, nil
+		}
+	}
+	if !shouldWritePRs {
+		console.Infof("Using existing PR descriptions from %s", prsFile)
+	}
+
+	report.summarize = shouldWriteSummary
+	return report, nil
+}
+
+// summarizeUser writes the summary (and brag doc, with generate_brag_doc) of the prs.md in
+// config.OutputDir
+func summarizeUser(ctx context.Context, svc *services, config Config) error {
+	prsFile := filepath.Join(config.OutputDir, "prs.md")
+
+	console.Infof("Generating summary of %s with %s...", prsFile, config.Summarizer)
+	if err := summarizeFile(ctx, svc, prsFile, filepath.Join(config.OutputDir, "summary.md"), defaultPrompt, config); err != nil {
+		return fmt.Errorf("error writing summary: %w", err)
+	}
+	if config.GenerateBragDoc {
+		console.Infof("Generating brag doc of %s with %s...", prsFile, config.Summarizer)
+		if err := writeBragDoc(ctx, svc, prsFile, filepath.Join(config.OutputDir, "brag.md"), config); err != nil {
+			return fmt.Errorf("error writing brag doc: %w", err)
+		}
+	}
+	return nil
+}
+
+// summarizeUsers summarizes each user's PRs, up to workers users at a time. Every user is
+// attempted even if another fails; the errors are returned together.
+func summarizeUsers(ctx context.Context, svc *services, userConfigs []Config, workers int) error {
+	errs := make([]error, len(userConfigs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(userConfigs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := summarizeUser(ctx, svc, userConfigs[i]); err != nil {
+					errs[i] = fmt.Errorf("failed to summarize PRs of user %s: %w", userConfigs[i].Username, err)
+				}
+			}
+		}()
 	}
+	for i := range userConfigs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return errors.Join(errs...)
+}
 
-	// Use copilot CLI to summarize the content
-	log.Printf("Generating summary with Copilot...")
-	summary, err := generateSummaryWithCopilot(prsFile, config.ExtraPrompt)
+// summarizeFile summarizes inputFile with basePrompt and writes the result to outputFile,
+// converting it when the output format is a document format
+func summarizeFile(ctx context.Context, svc *services, inputFile, outputFile, basePrompt string, config Config) error {
+	summary, err := generateSummary(ctx, svc.summarizer, inputFile, basePrompt, config)
 	if err != nil {
-		log.Fatalf("Error generating summary: %v", err)
+		return withExitCode(exitSummarizer, fmt.Errorf("error generating summary: %w", err))
+	}
+	if config.SummaryToStdout {
+		// Just the summary, for piping into other tools
+		_, err := fmt.Fprintln(os.Stdout, strings.TrimSpace(summary))
+		return err
+	}
+	if err := writeSummaryToOutput(summary, outputFile, config); err != nil {
+		return err
 	}
+	return writeDocuments(ctx, outputFile, config)
+}
+
+// checkMinExpectedPRs guards against silently producing an empty review (usually a
+// typo in the username or date range). It warns when fewer than MinExpectedPRs PRs were
+// found, or returns an error if config.Strict is set.
+func checkMinExpectedPRs(found int, config Config) error {
+	if found >= config.MinExpectedPRs {
+		return nil
+	}
+
+	msg := fmt.Sprintf("found only %d PRs for %s between %s and %s, fewer than min_expected_prs (%d); check the username, repos, and date range",
+		found, config.Username, config.SinceTime.Format(dateFormat), config.UntilTime.Format(dateFormat), config.MinExpectedPRs)
+	if config.Strict {
+		return fmt.Errorf("%s", msg)
+	}
+	console.Warnf("%s", msg)
+	return nil
+}
 
-	// Write summary to final output
-	if err := writeSummaryToOutput(summary, summaryFile); err != nil {
-		log.Fatalf("Error writing summary: %v", err)
+// usernameFromToken returns the login of the user the GitHub token belongs to
+func usernameFromToken(ctx context.Context, client *github.Client) (string, error) {
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the GitHub token's user: %w", err)
 	}
+	if user.GetLogin() == "" {
+		return "", errors.New("GitHub returned no login for the token's user")
+	}
+	return user.GetLogin(), nil
 }
 
 // getGitHubToken retrieves the GitHub token using the gh CLI
@@ -312,12 +1372,16 @@ func getGitHubToken() (string, error) {
 }
 
 // buildSearchQuery creates a search query for GitHub API
-func buildSearchQuery(repo NWO, config Config) string {
-	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s created:%s..%s",
-		repo.Owner, repo.Name, config.Username,
-		config.SinceTime.Format(dateFormat), config.UntilTime.Format(dateFormat))
+func buildSearchQuery(repo NWO, config Config) *searchQuery {
+	query := newSearchQuery(
+		qualifier(fmt.Sprintf("repo:%s/%s", repo.Owner, repo.Name)),
+		qualifier("is:pr"),
+		qualifier("is:merged"),
+		qualifier("author:"+config.Username),
+		windowQualifier(config),
+	)
 
-	log.Printf("GitHub search query for %s/%s: %s", repo.Owner, repo.Name, query)
+	console.Infof("GitHub search query for %s/%s: %s", repo.Owner, repo.Name, query)
 	return query
 }
 
@@ -333,18 +1397,51 @@ func countMergedPRs(ctx context.Context, client *github.Client, repo NWO, config
 		},
 	}
 
-	result, _, err := client.Search.Issues(ctx, query, opts)
+	// A query degraded to client-side filtering counts more PRs than it will yield, which
+	// is good enough for the progress bar
+	result, _, err := searchIssuesDegrading(ctx, client, query, opts)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count PRs: %w", err)
+		return 0, fmt.Errorf("failed to count PRs: %w", explainTokenAccessError(err, repo))
 	}
 
-	return result.GetTotal(), nil
+	// Pagination stops at the search cap however many PRs match
+	return min(result.GetTotal(), searchResultCap), nil
 }
 
-// getMergedPRsWithProgress retrieves merged PRs for a specific repository with progress tracking
-func getMergedPRsWithProgress(ctx context.Context, client *github.Client, repo NWO, config Config, bar *progressbar.ProgressBar) ([]PullRequestInfo, error) {
+// reconcilePRCount corrects the progress bar's max once a repository's PRs have been
+// fetched, since pagination can yield a different number than countMergedPRs predicted
+// (PRs deleted in between, a degraded query's client-side filtering, or a failed count).
+// It warns when the difference is large.
+func reconcilePRCount(bar *progressbar.ProgressBar, repo NWO, predicted, fetched int) {
+	if fetched == predicted {
+		return
+	}
+	if prCountsDiverge(predicted, fetched) {
+		console.Warnf("Expected %d PRs from %s/%s but fetched %d; adjusting the progress bar", predicted, repo.Owner, repo.Name, fetched)
+	}
+	bar.ChangeMax(bar.GetMax() - predicted + fetched)
+}
+
+// prCountsDiverge reports whether the number of PRs fetched differs from the predicted
+// number by more than 10%, ignoring differences of a few PRs
+func prCountsDiverge(predicted, fetched int) bool {
+	diff := fetched - predicted
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > max(predicted/10, 5)
+}
+
+// getMergedPRsWithProgress retrieves merged PRs for a specific repository with progress tracking.
+// If a page of results can't be fetched, the PRs from earlier pages are returned along with the error.
+func getMergedPRsWithProgress(ctx context.Context, svc *services, repo NWO, config Config, bar *progressbar.ProgressBar) ([]PullRequestInfo, error) {
 	var allPRs []PullRequestInfo
 
+	client, err := svc.githubClient()
+	if err != nil {
+		return nil, err
+	}
+
 	query := buildSearchQuery(repo, config)
 
 	opts := &github.SearchOptions{
@@ -356,40 +1453,19 @@ func getMergedPRsWithProgress(ctx context.Context, client *github.Client, repo N
 	}
 
 	for {
-		result, resp, err := client.Search.Issues(ctx, query, opts)
+		result, resp, err := searchIssuesDegrading(ctx, client, query, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to search PRs: %w", err)
+			// Keep the pages fetched so far rather than discarding them
+			return allPRs, fmt.Errorf("failed to search PRs (page %d): %w", max(opts.Page, 1), explainTokenAccessError(err, repo))
 		}
+		dumpSearchResults(svc.debugSearch, query.String(), opts.Page, result)
 
-		for _, issue := range result.Issues {
+		for _, issue := range query.filter(result.Issues) {
 			if bar != nil {
 				bar.Describe(fmt.Sprintf("Processing PR #%d from %s/%s", issue.GetNumber(), repo.Owner, repo.Name))
 			}
 
-			// Convert GitHub issue to our PR info structure
-			prInfo := PullRequestInfo{
-				Repository:  fmt.Sprintf("%s/%s", repo.Owner, repo.Name),
-				Title:       issue.GetTitle(),
-				Description: issue.GetBody(),
-				URL:         issue.GetHTMLURL(),
-				CreatedAt:   issue.GetCreatedAt().Time,
-			}
-
-			// Get the actual PR to get merge information and full description
-			pr, _, err := client.PullRequests.Get(ctx, repo.Owner, repo.Name, issue.GetNumber())
-			if err != nil {
-				log.Printf("Warning: failed to get PR details for #%d: %v", issue.GetNumber(), err)
-			} else {
-				// Update description with PR body if available (more detailed than issue body)
-				if pr.GetBody() != "" {
-					prInfo.Description = pr.GetBody()
-				}
-				// Set merge time if available
-				if pr.MergedAt != nil {
-					mergedAt := pr.GetMergedAt().Time
-					prInfo.MergedAt = &mergedAt
-				}
-			}
+			prInfo := getPRInfo(ctx, client, svc.prCache, repo, issue, config)
 
 			allPRs = append(allPRs, prInfo)
 			if bar != nil {
@@ -406,84 +1482,318 @@ func getMergedPRsWithProgress(ctx context.Context, client *github.Client, repo N
 	return allPRs, nil
 }
 
+// getPRInfo converts a search result into our PR info structure, fetching the PR itself
+// (or taking it from cache, if not nil) for merge information and the full description.
+// The description is redacted here, so that every output has it redacted.
+func getPRInfo(ctx context.Context, client *github.Client, cache *prCache, repo NWO, issue *github.Issue, config Config) PullRequestInfo {
+	prInfo := prInfoFromIssue(repo, issue)
+	if details, ok := getPRDetails(ctx, client, cache, repo, issue.GetNumber()); ok {
+		details.apply(&prInfo)
+	}
+	prInfo.Description = redact(prInfo.Description, config.RedactRules)
+	return prInfo
+}
+
+// getPRDetails fetches the details of a PR beyond its search result, or takes them from
+// cache if it is not nil and has them. It reports whether the details could be had.
+func getPRDetails(ctx context.Context, client *github.Client, cache *prCache, repo NWO, number int) (prDetails, bool) {
+	if cache != nil {
+		if details, ok := cache.Load(repo, number); ok {
+			return details, true
+		}
+	}
+
+	// Get the actual PR to get merge information and full description
+	pr, _, err := client.PullRequests.Get(ctx, repo.Owner, repo.Name, number)
+	if err != nil {
+		console.Warnf("Failed to get PR details for #%d: %v", number, explainTokenAccessError(err, repo))
+		return prDetails{}, false
+	}
+
+	details := prDetailsFromPR(pr)
+	if cache != nil {
+		if err := cache.Store(repo, number, details); err != nil {
+			console.Warnf("Failed to cache PR details for #%d: %v", number, err)
+		}
+	}
+	return details, true
+}
+
+// prInfoFromIssue converts a GitHub search result to our PR info structure
+func prInfoFromIssue(repo NWO, issue *github.Issue) PullRequestInfo {
+	return PullRequestInfo{
+		Author:      issue.GetUser().GetLogin(),
+		Repository:  fmt.Sprintf("%s/%s", repo.Owner, repo.Name),
+		Title:       issue.GetTitle(),
+		Description: issue.GetBody(),
+		URL:         issue.GetHTMLURL(),
+		Number:      issue.GetNumber(),
+		CreatedAt:   issue.GetCreatedAt().Time,
+
+		AuthorAssociation: issue.GetAuthorAssociation(),
+	}
+}
+
+// outputWriter writes output either to stdout or, for a named file, to a temporary
+// file in the same directory that Commit renames into place. Readers therefore never
+// observe a partially written file, even if the process is killed mid-write.
+type outputWriter struct {
+	*os.File
+	path      string
+	mode      os.FileMode
+	committed bool
+}
+
 // getOutputWriter returns the appropriate writer for the given output file
-func getOutputWriter(outputFile string) (*os.File, error) {
+func getOutputWriter(outputFile string) (*outputWriter, error) {
 	if outputFile != "" {
-		writer, err := os.Create(outputFile)
+		dir, base := filepath.Split(outputFile)
+		if dir == "" {
+			dir = "."
+		}
+		tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
 		if err != nil {
 			return nil, fmt.Errorf("failed to create output file %s: %w", outputFile, err)
 		}
-		return writer, nil
+		return &outputWriter{File: tmp, path: outputFile, mode: 0644}, nil
+	}
+	return &outputWriter{File: os.Stdout}, nil
+}
+
+// Commit flushes the temporary file and renames it to the destination path
+func (w *outputWriter) Commit() error {
+	if w.path == "" || w.committed {
+		return nil
 	}
-	return os.Stdout, nil
+
+	tmpName := w.File.Name()
+	if err := w.File.Sync(); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", w.path, err)
+	}
+	if err := w.File.Chmod(w.mode); err != nil {
+		return fmt.Errorf("failed to set permissions on output file %s: %w", w.path, err)
+	}
+	if err := w.File.Close(); err != nil {
+		return fmt.Errorf("failed to write output file %s: %w", w.path, err)
+	}
+	if err := os.Rename(tmpName, w.path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to move output file into place at %s: %w", w.path, err)
+	}
+
+	w.committed = true
+	return nil
 }
 
-// outputPRs outputs the PR information as Markdown
-func outputPRs(prs []PullRequestInfo, outputFile string) error {
+// Close discards the temporary file if it was never committed
+func (w *outputWriter) Close() error {
+	if w.path == "" || w.committed {
+		return nil
+	}
+
+	tmpName := w.File.Name()
+	w.File.Close()
+	return os.Remove(tmpName)
+}
+
+// outputPRs outputs the PR information as Markdown, describing the repositories from
+// contexts (with include_repo_context)
+func outputPRs(prs, openPRs []PullRequestInfo, contexts map[string]repoContext, outputFile string, config Config) error {
 	writer, err := getOutputWriter(outputFile)
 	if err != nil {
 		return err
 	}
 	if outputFile != "" {
 		defer writer.Close()
-		log.Printf("Writing PR details to %s", outputFile)
+		console.Infof("Writing PR details to %s", outputFile)
 	}
 
-	// Write markdown header
-	fmt.Fprintf(writer, "# Merged Pull Requests\n\n")
-	fmt.Fprintf(writer, "Found %d merged pull requests.\n\n", len(prs))
+	// Write markdown header. Sections below are offset from this top level.
+	top := 1 + config.HeadingOffset
+	fmt.Fprintf(writer, "%s Merged Pull Requests\n\n", heading(top))
+	if config.DiffAgainst != "" {
+		fmt.Fprintf(writer, "Found %d merged pull requests new since last report (`%s`).\n\n", len(prs), filepath.Base(config.DiffAgainst))
+	} else {
+		fmt.Fprintf(writer, "Found %d merged pull requests.\n\n", len(prs))
+	}
+	if config.ShowLeadTime {
+		if average, known := averageLeadTime(prs); known > 0 {
+			fmt.Fprintf(writer, "Average lead time from creation to merge: %s (over the %d pull requests with a known merge time).\n\n", formatDuration(average), known)
+		}
+	}
+	if config.ContributionScore {
+		fmt.Fprintf(writer, "Contribution score: %.1f (a rough heuristic, not a measure of impact: %s).\n\n",
+			contributionScore(prs, config.Weights), scoreFormula(config.Weights))
+	}
 
 	if len(prs) == 0 {
-		fmt.Fprintf(writer, "*No merged PRs found.*\n")
-		return nil
+		fmt.Fprintf(writer, "%s\n\n", config.NoPRsText)
+	} else {
+		writeRepoContext(writer, prs, contexts, top+1, config)
+		if err := writeRepoGroups(writer, prs, top+1, config); err != nil {
+			return err
+		}
 	}
-
-	// Group PRs by repository
-	repoGroups := make(map[string][]PullRequestInfo)
-	for _, pr := range prs {
-		repoGroups[pr.Repository] = append(repoGroups[pr.Repository], pr)
+	if config.ShowEmptyRepos {
+		writeEmptyRepos(writer, prs, top+1, config)
 	}
 
-	// Output each repository group
-	for repo, repoPRs := range repoGroups {
-		fmt.Fprintf(writer, "## %s\n\n", repo)
+	// Open PRs get their own top-level section after the merged ones
+	if err := writeOpenPRs(writer, openPRs, top, config); err != nil {
+		return err
+	}
 
-		for _, pr := range repoPRs {
-			// PR title as H3 with link
-			fmt.Fprintf(writer, "### [%s](%s)\n\n", pr.Title, pr.URL)
+	return writer.Commit()
+}
 
-			// Metadata table
-			fmt.Fprintf(writer, "| Field | Value |\n")
-			fmt.Fprintf(writer, "|-------|-------|\n")
-			fmt.Fprintf(writer, "| **Created** | %s |\n", pr.CreatedAt.Format("2006-01-02 15:04:05"))
-			fmt.Fprintf(writer, "| **Link** | <%s> |\n", pr.URL)
+// outputPRFormats renders the PRs once for each requested output format. prs.md is
+// always written, since the summarizer reads it, and prs.json is written beforehand as
+// the snapshot of everything fetched.
+func outputPRFormats(ctx context.Context, svc *services, prs []PullRequestInfo, report *userReport, prsFile string, config Config) error {
+	console.Infof("Writing PR descriptions to %s", prsFile)
+	if err := outputPRs(prs, report.OpenPRs, svc.repoContexts, prsFile, config); err != nil {
+		return fmt.Errorf("error writing PR descriptions to output file: %w", err)
+	}
 
-			if pr.MergedAt != nil {
-				fmt.Fprintf(writer, "| **Merged** | %s |\n", pr.MergedAt.Format("2006-01-02 15:04:05"))
-			} else {
-				fmt.Fprintf(writer, "| **Merged** | *Not available* |\n")
+	for _, format := range config.OutputFormats {
+		switch format {
+		case outputFormatHTML:
+			report.Profile = svc.profiles.Get(ctx, config.Username)
+			if err := outputPRsHTML(prs, report.Profile, filepath.Join(config.OutputDir, "prs.html"), config); err != nil {
+				return fmt.Errorf("error writing HTML report: %w", err)
 			}
+		case outputFormatPDF, outputFormatDOCX:
+			if config.DocumentIncludePRs {
+				if err := writeDocument(ctx, prsFile, format, config); err != nil {
+					return fmt.Errorf("error converting PR descriptions: %w", err)
+				}
+			}
+		}
+	}
 
-			fmt.Fprintf(writer, "\n")
+	if config.EmitICal {
+		if err := outputICal(prs, filepath.Join(config.OutputDir, "prs.ics")); err != nil {
+			return fmt.Errorf("error writing calendar: %w", err)
+		}
+	}
+	return nil
+}
 
-			// PR description - extract appropriate description based on repository
-			if strings.TrimSpace(pr.Description) != "" {
-				fmt.Fprintf(writer, "#### Description\n\n")
+// heading returns the markdown prefix for a heading of the given level (e.g. "###" for 3).
+// Levels past the deepest Markdown supports, which a heading_offset can produce for
+// nested sections, are kept at that deepest level.
+func heading(level int) string {
+	return strings.Repeat("#", min(level, maxHeadingLevel))
+}
 
-				descriptionText := getRepositorySpecificDescription(pr.Repository, pr.Description)
-				fmt.Fprintf(writer, "%s\n\n", descriptionText)
+// sectionSeparator returns the Markdown written between PRs, "---" unless configured
+func sectionSeparator(config Config) string {
+	if config.SectionSeparator != nil {
+		return *config.SectionSeparator
+	}
+	return "---"
+}
+
+// writeRepoGroups writes PRs grouped by repository, with each repository as a heading
+// of the given level and each PR one level below it
+func writeRepoGroups(writer io.Writer, prs []PullRequestInfo, level int, config Config) error {
+	// Output each repository group
+	for _, group := range orderRepoGroups(prs, config) {
+		fmt.Fprintf(writer, "%s %s\n\n", heading(level), repoHeading(group, config))
+
+		writePR := func(pr PullRequestInfo, level int) error {
+			if config.PRTemplateParsed != nil {
+				if err := writePRFromTemplate(writer, pr, level, config); err != nil {
+					return err
+				}
 			} else {
-				fmt.Fprintf(writer, "#### Description\n\n*No description provided.*\n\n")
+				writePRBlock(writer, pr, level, group.Collapsed, config)
 			}
 
 			// Separator between PRs
-			fmt.Fprintf(writer, "---\n\n")
+			if separator := sectionSeparator(config); separator != "" {
+				fmt.Fprintf(writer, "%s\n\n", separator)
+			}
+			return nil
 		}
-	}
 
+		// With group_stacked_prs, each chain is written where its first PR would be
+		stackOf := make(map[int][]int)
+		if config.GroupStackedPRs {
+			for _, stack := range findStacks(group.PRs) {
+				for _, i := range stack {
+					stackOf[i] = stack
+				}
+			}
+		}
+
+		for i, pr := range group.PRs {
+			stack, stacked := stackOf[i]
+			if !stacked {
+				if err := writePR(pr, level+1); err != nil {
+					return err
+				}
+				continue
+			}
+			if i != slices.Min(stack) {
+				continue
+			}
+
+			fmt.Fprintf(writer, "%s Stacked PRs: %s (%d PRs)\n\n", heading(level+1), group.PRs[stack[0]].Title, len(stack))
+			for position, j := range stack {
+				member := group.PRs[j]
+				member.Stack = fmt.Sprintf("%d of %d", position+1, len(stack))
+				if err := writePR(member, level+2); err != nil {
+					return err
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// writePRBlock writes the default layout for one PR: a linked heading at the given level,
+// a metadata table, and the description
+func writePRBlock(writer io.Writer, pr PullRequestInfo, level int, showRepository bool, config Config) {
+	// PR title as a subheading with link
+	var badges string
+	if pr.ImpactTag != "" {
+		badges += fmt.Sprintf(" `%s`", pr.ImpactTag)
+	}
+	if pr.RevertedBy != "" {
+		badges += " ⚠ later reverted"
+	}
+	fmt.Fprintf(writer, "%s [%s](%s)%s\n\n", heading(level), pr.Title, pr.URL, badges)
+
+	// Metadata table
+	fmt.Fprintf(writer, "| Field | Value |\n")
+	fmt.Fprintf(writer, "|-------|-------|\n")
+	for _, field := range config.Metadata {
+		if label, value, ok := field.Row(pr, showRepository, config); ok {
+			fmt.Fprintf(writer, "| **%s** | %s |\n", label, value)
+		}
+	}
+
+	fmt.Fprintf(writer, "\n")
+
+	// PR description - extract appropriate description based on repository
+	if strings.TrimSpace(pr.Description) != "" {
+		fmt.Fprintf(writer, "%s Description\n\n", heading(level+1))
+
+		descriptionText, truncated := extractDescription(pr, config)
+		boilerplate := config.FlagBoilerplate && looksLikeBoilerplate(descriptionText, config.BoilerplateRules, config.BoilerplateMinMatches)
+		if truncated {
+			descriptionText = fmt.Sprintf("%s … [truncated]\n\n[Read the full description](%s)", descriptionText, pr.URL)
+		}
+		fmt.Fprintf(writer, "%s\n\n", descriptionText)
+		if boilerplate {
+			fmt.Fprintf(writer, "*Note: this description reads like generated boilerplate, so it may say little about the change itself.*\n\n")
+		}
+	} else {
+		fmt.Fprintf(writer, "%s Description\n\n%s\n\n", heading(level+1), config.EmptyDescriptionText)
+	}
+}
+
 // filterHTMLComments removes HTML comments from the given text while preserving line structure
 func filterHTMLComments(text string) string {
 	lines := strings.Split(text, "\n")
@@ -544,6 +1854,13 @@ func filterHTMLCommentsAndEmptyLinesAtStart(lines []string) []string {
 // extractDescriptionForTSS extracts only the first section from a PR description
 // that follows the standard template format
 func extractDescriptionForTSS(description string) string {
+	text, _ := extractTSSSection(description)
+	return text
+}
+
+// extractTSSSection is extractDescriptionForTSS, also returning the heading whose section
+// it couldn't use if it fell back to the original description
+func extractTSSSection(description string) (string, []string) {
 	lines := strings.Split(description, "\n")
 	var firstSection []string
 	inFirstSection := false
@@ -552,7 +1869,7 @@ func extractDescriptionForTSS(description string) string {
 		trimmedLine := strings.TrimSpace(line)
 
 		// Check if this is the start of the first section
-		if strings.HasPrefix(trimmedLine, "### What are you trying to accomplish?") {
+		if strings.HasPrefix(trimmedLine, accomplishHeading) {
 			inFirstSection = true
 			continue // Skip the section header itself
 		}
@@ -574,15 +1891,25 @@ func extractDescriptionForTSS(description string) string {
 
 	// If we didn't find the standard format, return the original description
 	if result == "" {
-		return description
+		return description, []string{accomplishHeading}
 	}
 
-	return result
+	return result, nil
 }
 
+// extractDescriptionForDotcom extracts the "What are you trying to accomplish?" section of
+// a PR description, falling back to everything before the approach section
 func extractDescriptionForDotcom(description string) string {
+	text, _ := extractDotcomSection(description)
+	return text
+}
+
+// extractDotcomSection is extractDescriptionForDotcom, also returning the headings whose
+// sections it couldn't use: the accomplish heading if it fell back, and the approach
+// heading too if that left the whole description
+func extractDotcomSection(description string) (string, []string) {
 	// First, try to extract content from "### What are you trying to accomplish?" section
-	accomplishMarker := "### What are you trying to accomplish?"
+	accomplishMarker := accomplishHeading
 	accomplishIndex := strings.Index(description, accomplishMarker)
 
 	if accomplishIndex != -1 {
@@ -612,17 +1939,19 @@ func extractDescriptionForDotcom(description string) string {
 
 		// If we found non-empty content, return it
 		if extractedContent != "" {
-			return extractedContent
+			return extractedContent, nil
 		}
 	}
 
 	// Fallback: Look for the "### What approach did you choose and why?" section and truncate there
-	approachMarker := "### What approach did you choose and why?"
+	approachMarker := approachHeading
 	index := strings.Index(description, approachMarker)
 
 	var contentToProcess string
+	missed := []string{accomplishHeading}
 	if index == -1 {
 		contentToProcess = description
+		missed = append(missed, approachHeading)
 	} else {
 		// Extract everything before the marker
 		contentToProcess = description[:index]
@@ -630,77 +1959,81 @@ func extractDescriptionForDotcom(description string) string {
 
 	// Filter out HTML comments and clean up the content
 	result := filterHTMLComments(contentToProcess)
-	return strings.TrimSpace(result)
+	return strings.TrimSpace(result), missed
 }
 
-// getRepositorySpecificDescription returns the appropriate description text based on the repository
-func getRepositorySpecificDescription(repository, description string) string {
-	switch repository {
-	case "github/token-scanning-service":
-		return extractDescriptionForTSS(description)
-	case "github/github":
-		return extractDescriptionForDotcom(description)
-	default:
-		// Use full description for other repositories
-		return description
-	}
-}
-
-// generateSummaryWithCopilot uses the copilot CLI to generate a summary of the PR descriptions
-func generateSummaryWithCopilot(prsFilePath, extraPrompt string) (string, error) {
-	// Get the directory containing the prs file and the filename
-	prsDir, err := filepath.Abs(filepath.Dir(prsFilePath))
-	if err != nil {
-		return "", fmt.Errorf("failed to get absolute path for directory: %w", err)
+// generateSummary asks the summarizer for a summary of the PR descriptions in prsFilePath,
+// using basePrompt (which refers to the file via %s) plus any extra instructions
+func generateSummary(ctx context.Context, summarizer Summarizer, prsFilePath, basePrompt string, config Config) (string, error) {
+	// A long input is summarized in chunks first, and the summary made from their summaries
+	if config.SummaryChunkChars > 0 {
+		chunkSummaries, err := summarizeChunks(ctx, summarizer, prsFilePath, config)
+		if err != nil {
+			return "", err
+		}
+		if chunkSummaries != "" {
+			defer os.Remove(chunkSummaries)
+			prsFilePath = chunkSummaries
+		}
 	}
-	prsFileName := filepath.Base(prsFilePath)
 
-	// Build the prompt starting with the default, using just the filename
-	prompt := fmt.Sprintf(defaultPrompt, prsFileName)
+	// Build the prompt starting with the base prompt, referring to the file the way the
+	// backend expects
+	prompt := fmt.Sprintf(basePrompt, inputReference(config.Summarizer, prsFilePath))
 
 	// Add custom instructions if provided
-	if extraPrompt != "" {
+	if config.ExtraPrompt != "" {
 		// Append additional instructions to the default prompt
-		prompt = fmt.Sprintf("%s\n\nAdditional instructions:\n%s", prompt, strings.TrimSpace(extraPrompt))
+		prompt = fmt.Sprintf("%s\n\nAdditional instructions:\n%s", prompt, strings.TrimSpace(config.ExtraPrompt))
 	}
 
-	log.Printf("Copilot prompt: %s", prompt)
-
-	// Use copilot CLI with the directory added and reference the filename in the prompt
-	cmd := exec.Command("copilot", "--disable-builtin-mcps", "--deny-tool", "--no-color", "--no-custom-instructions", "--add-dir", prsDir, "-p", prompt)
-	cmd.Dir = prsDir
-
-	output, err := cmd.Output()
-	if err != nil {
-		// If there's an error, try to get stderr for more details
-		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("failed to run copilot CLI: %w\nStderr: %s", err, string(exitError.Stderr))
-		}
-		return "", fmt.Errorf("failed to run copilot CLI: %w (make sure copilot CLI is installed and available)", err)
-	}
-
-	summary := strings.TrimSpace(string(output))
-	if summary == "" {
-		return "", fmt.Errorf("copilot CLI returned empty summary")
-	}
-
-	return summary, nil
+	return summarizer.Summarize(ctx, SummaryRequest{
+		SystemPrompt: strings.TrimSpace(config.SystemPrompt),
+		Prompt:       prompt,
+		InputFile:    prsFilePath,
+	})
 }
 
 // writeSummaryToOutput writes the summary to the specified output file or stdout
-func writeSummaryToOutput(summary, outputFile string) error {
+func writeSummaryToOutput(summary, outputFile string, config Config) error {
 	writer, err := getOutputWriter(outputFile)
 	if err != nil {
 		return err
 	}
 	if outputFile != "" {
 		defer writer.Close()
-		log.Printf("Writing summary to %s", outputFile)
+		console.Infof("Writing summary to %s", outputFile)
 	}
 
-	// Write the summary
-	fmt.Fprintf(writer, "# PR Summary\n\n")
-	fmt.Fprintf(writer, "%s\n", summary)
+	fmt.Fprint(writer, formatSummary(summary, config))
 
-	return nil
+	return writer.Commit()
+}
+
+// summaryTitle returns the heading written at the top of summaries, "" for none
+func summaryTitle(config Config) string {
+	if config.SummaryTitle != nil {
+		return *config.SummaryTitle
+	}
+	return "PR Summary"
+}
+
+// formatSummary wraps the generated summary in its title and the configured prefix and
+// suffix, which are copied verbatim apart from separating them with a blank line
+func formatSummary(summary string, config Config) string {
+	title := summaryTitle(config)
+
+	var parts []string
+	if config.SummaryPrefix != "" {
+		parts = append(parts, strings.TrimRight(config.SummaryPrefix, "\n"))
+	}
+	if title != "" {
+		parts = append(parts, "# "+title)
+	}
+	parts = append(parts, summary)
+	if config.SummarySuffix != "" {
+		parts = append(parts, strings.TrimRight(config.SummarySuffix, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n") + "\n"
 }
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/google/go-github/v56/github"
+	"github.com/schollz/progressbar/v3"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -416,3 +424,173 @@ Blah blah
 		})
 	}
 }
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	date := func(s string) time.Time {
+		d, err := time.Parse(dateFormat, s)
+		if err != nil {
+			t.Fatalf("bad test date %s: %v", s, err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name          string
+		config        Config
+		expectedSince time.Time
+		expectedUntil time.Time
+		wantErr       string
+	}{
+		{
+			name:          "neither uses default days back from now",
+			expectedSince: now.AddDate(0, 0, -defaultDays),
+			expectedUntil: now,
+		},
+		{
+			name:          "days only",
+			config:        Config{Days: 7},
+			expectedSince: now.AddDate(0, 0, -7),
+			expectedUntil: now,
+		},
+		{
+			name:          "since and until",
+			config:        Config{Since: "2025-01-01", Until: "2025-03-31"},
+			expectedSince: date("2025-01-01"),
+			expectedUntil: date("2025-03-31"),
+		},
+		{
+			name:          "since only runs until now",
+			config:        Config{Since: "2025-05-01"},
+			expectedSince: date("2025-05-01"),
+			expectedUntil: now,
+		},
+		{
+			name:          "until only uses default days back from until",
+			config:        Config{Until: "2025-03-31"},
+			expectedSince: date("2025-03-31").AddDate(0, 0, -defaultDays),
+			expectedUntil: date("2025-03-31"),
+		},
+		{
+			name:          "until and days",
+			config:        Config{Until: "2025-03-31", Days: 10},
+			expectedSince: date("2025-03-21"),
+			expectedUntil: date("2025-03-31"),
+		},
+		{
+			name:    "since and days",
+			config:  Config{Since: "2025-05-01", Days: 10},
+			wantErr: "days cannot be combined with since",
+		},
+		{
+			name:    "since, until, and days",
+			config:  Config{Since: "2025-01-01", Until: "2025-03-31", Days: 10},
+			wantErr: "days cannot be combined with since",
+		},
+		{
+			name:    "negative days",
+			config:  Config{Days: -1},
+			wantErr: "days cannot be negative",
+		},
+		{
+			name:    "since after until",
+			config:  Config{Since: "2025-04-01", Until: "2025-03-31"},
+			wantErr: "since (2025-04-01) is after until (2025-03-31)",
+		},
+		{
+			name:    "since in the future",
+			config:  Config{Since: "2025-07-01"},
+			wantErr: "is after until",
+		},
+		{
+			name:    "bad since",
+			config:  Config{Since: "May 1"},
+			wantErr: "invalid since date format 'May 1'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			err := config.parseDateRange(now)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSince, config.SinceTime)
+			assert.Equal(t, tt.expectedUntil, config.UntilTime)
+		})
+	}
+}
+
+func TestReconcilePRCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicted int
+		fetched   int
+		expected  int
+		diverges  bool
+	}{
+		{"as predicted", 40, 40, 100, false},
+		{"a few fewer", 40, 37, 97, false},
+		{"many fewer", 40, 20, 80, true},
+		{"more than predicted", 40, 50, 110, true},
+		{"count failed", 0, 3, 103, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := progressbar.NewOptions(100, progressbar.OptionSetWriter(io.Discard))
+			reconcilePRCount(bar, NWO{Owner: "owner", Name: "repo"}, tt.predicted, tt.fetched)
+			assert.Equal(t, tt.expected, bar.GetMax())
+			assert.Equal(t, tt.diverges, prCountsDiverge(tt.predicted, tt.fetched))
+		})
+	}
+}
+
+// newTestGitHubClient returns a GitHub client for a fake API served by handler, which is
+// shut down when the test ends
+func newTestGitHubClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse test server URL: %v", err)
+	}
+	client := github.NewClient(server.Client())
+	client.BaseURL = baseURL
+	return client
+}
+
+func TestUsernameFromToken(t *testing.T) {
+	login := "octocat"
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"login": "` + login + `"}`))
+	}))
+
+	username, err := usernameFromToken(context.Background(), client)
+	assert.NoError(t, err)
+	assert.Equal(t, "octocat", username)
+
+	login = ""
+	_, err = usernameFromToken(context.Background(), client)
+	assert.ErrorContains(t, err, "no login")
+
+	t.Run("only without a configured username", func(t *testing.T) {
+		config := Config{OutputDir: "out", Repos: []string{"owner/repo"}}
+		assert.NoError(t, config.Parse())
+		assert.True(t, config.UsernameFromToken)
+		assert.Empty(t, config.Usernames)
+
+		config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+		assert.NoError(t, config.Parse())
+		assert.False(t, config.UsernameFromToken)
+		assert.Equal(t, []string{"someone"}, config.Usernames)
+	})
+}
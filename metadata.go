@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// metadataField is one row of a PR's metadata table. Row returns the row's label and
+// value for a PR, or false if the PR has nothing to show for it.
+type metadataField struct {
+	Key string
+	Row func(pr PullRequestInfo, showRepository bool, config Config) (label, value string, ok bool)
+}
+
+// metadataFields are the rows a PR's metadata table can have, in their default order
+var metadataFields = []metadataField{
+	{"repository", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		// Shown in the Miscellaneous section by default, and always when chosen
+		return "Repository", repoLabel(pr.Repository, config), showRepository || len(config.MetadataFields) > 0
+	}},
+	{"created", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Created", pr.CreatedAt.Format("2006-01-02 15:04:05"), true
+	}},
+	{"link", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Link", fmt.Sprintf("<%s>", pr.URL), true
+	}},
+	{"role", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Role", fmt.Sprintf("Co-author (PR opened by %s)", pr.Author), pr.CoAuthored
+	}},
+	{"merged", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		switch {
+		case pr.Open:
+			return "State", "Open", true
+		case pr.MergedAt != nil:
+			return "Merged", pr.MergedAt.Format("2006-01-02 15:04:05"), true
+		default:
+			return "Merged", "*Not available*", true
+		}
+	}},
+	{"reverted_by", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Reverted by", fmt.Sprintf("<%s>", pr.RevertedBy), pr.RevertedBy != ""
+	}},
+	{"checks", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		switch {
+		case pr.Open:
+			return "", "", false
+		case pr.Checks != nil:
+			return "Checks", pr.Checks.String(), true
+		case pr.MergeCommitSHA == "":
+			return "Checks", "*Merge commit unknown*", true
+		default:
+			return "Checks", "*Not available*", true
+		}
+	}},
+	{"stack", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Stack", pr.Stack, pr.Stack != ""
+	}},
+	{"lead_time", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		if pr.Open {
+			return "", "", false
+		}
+		if leadTime, ok := prLeadTime(pr); ok {
+			return "Lead time", formatDuration(leadTime), true
+		}
+		return "Lead time", "*Unknown*", true
+	}},
+	{"review_depth", func(pr PullRequestInfo, showRepository bool, config Config) (string, string, bool) {
+		return "Review depth", fmt.Sprintf("%d (%d comments, %d review comments)", reviewDepth(pr), pr.Comments, pr.ReviewComments), true
+	}},
+}
+
+// metadataFieldByKey returns the metadata field with the given key
+func metadataFieldByKey(key string) (metadataField, bool) {
+	for _, field := range metadataFields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return metadataField{}, false
+}
+
+// metadataFieldKeys returns the keys of all metadata fields in their default order
+func metadataFieldKeys() []string {
+	var keys []string
+	for _, field := range metadataFields {
+		keys = append(keys, field.Key)
+	}
+	return keys
+}
+
+// metadataFieldOption returns the option a metadata field's data depends on, and whether
+// it is enabled. Fields that depend on no option are always enabled.
+func metadataFieldOption(key string, config Config) (string, bool) {
+	switch key {
+	case "checks":
+		return "include_checks", config.IncludeChecks
+	case "lead_time":
+		return "show_lead_time", config.ShowLeadTime
+	case "review_depth":
+		return "contribution_score", config.ContributionScore
+	default:
+		return "", true
+	}
+}
+
+// parseMetadataFields returns the metadata fields to render, in order: the configured
+// ones, or by default every field whose option is enabled
+func parseMetadataFields(configured []string, config Config) ([]metadataField, error) {
+	if len(configured) == 0 {
+		var fields []metadataField
+		for _, field := range metadataFields {
+			if _, enabled := metadataFieldOption(field.Key, config); enabled {
+				fields = append(fields, field)
+			}
+		}
+		return fields, nil
+	}
+
+	var fields []metadataField
+	seen := make(map[string]bool)
+	for _, key := range configured {
+		key = strings.ToLower(strings.TrimSpace(key))
+		field, ok := metadataFieldByKey(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown metadata_fields entry '%s': expected one of %s", key, strings.Join(metadataFieldKeys(), ", "))
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("metadata_fields lists '%s' more than once", key)
+		}
+		if option, enabled := metadataFieldOption(key, config); !enabled {
+			return nil, fmt.Errorf("metadata_fields entry '%s' requires %s", key, option)
+		}
+		seen[key] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
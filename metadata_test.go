@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataFields(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	merged := created.Add(26 * time.Hour)
+	pr := PullRequestInfo{
+		Repository: "owner/repo", Title: "Change", URL: "https://github.com/owner/repo/pull/1",
+		CreatedAt: created, MergedAt: &merged, Comments: 2,
+	}
+
+	render := func(config Config) string {
+		t.Helper()
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		var buf strings.Builder
+		writePRBlock(&buf, pr, 2, false, config)
+		output := buf.String()
+		return output[strings.Index(output, "| Field |"):strings.Index(output, "### Description")]
+	}
+
+	t.Run("default", func(t *testing.T) {
+		table := render(Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}})
+		assert.Equal(t, "| Field | Value |\n|-------|-------|\n"+
+			"| **Created** | 2024-05-01 09:00:00 |\n"+
+			"| **Link** | <https://github.com/owner/repo/pull/1> |\n"+
+			"| **Merged** | 2024-05-02 11:00:00 |\n\n", table)
+	})
+
+	t.Run("configured order and selection", func(t *testing.T) {
+		table := render(Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"},
+			ShowLeadTime: true, ContributionScore: true, MetadataFields: []string{"lead_time", "Merged", "repository", "review_depth"}})
+		assert.Equal(t, "| Field | Value |\n|-------|-------|\n"+
+			"| **Lead time** | 1d 2h |\n"+
+			"| **Merged** | 2024-05-02 11:00:00 |\n"+
+			"| **Repository** | owner/repo |\n"+
+			"| **Review depth** | 2 (2 comments, 0 review comments) |\n\n", table)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			fields   []string
+			expected string
+		}{
+			{[]string{"created", "author"}, "unknown metadata_fields entry 'author'"},
+			{[]string{"link", "link"}, "metadata_fields lists 'link' more than once"},
+			{[]string{"checks"}, "metadata_fields entry 'checks' requires include_checks"},
+			{[]string{"lead_time"}, "metadata_fields entry 'lead_time' requires show_lead_time"},
+			{[]string{"review_depth"}, "metadata_fields entry 'review_depth' requires contribution_score"},
+		}
+		for _, tt := range tests {
+			config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, MetadataFields: tt.fields}
+			assert.ErrorContains(t, config.Parse(), tt.expected)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// buildOpenSearchQuery builds the search query for the user's PRs that are still open and
+// were worked on during the date range
+func buildOpenSearchQuery(repo NWO, config Config) *searchQuery {
+	query := newSearchQuery(
+		qualifier(fmt.Sprintf("repo:%s/%s", repo.Owner, repo.Name)),
+		qualifier("is:pr"),
+		qualifier("is:open"),
+		qualifier("author:"+config.Username),
+		createdBeforeQualifier(config.UntilTime),
+		updatedSinceQualifier(config.SinceTime),
+	)
+
+	console.Infof("GitHub search query for open PRs in %s/%s: %s", repo.Owner, repo.Name, query)
+	return query
+}
+
+// getOpenPRs fetches the user's open PRs in a repository. The search results already
+// hold everything shown for an open PR, so unlike merged PRs no per-PR call is made.
+func getOpenPRs(ctx context.Context, svc *services, repo NWO, config Config) ([]PullRequestInfo, error) {
+	var openPRs []PullRequestInfo
+
+	client, err := svc.githubClient()
+	if err != nil {
+		return nil, err
+	}
+
+	query := buildOpenSearchQuery(repo, config)
+	opts := &github.SearchOptions{
+		Sort:  "updated",
+		Order: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: perPageLimit,
+		},
+	}
+
+	for {
+		result, resp, err := searchIssuesDegrading(ctx, client, query, opts)
+		if err != nil {
+			return openPRs, fmt.Errorf("failed to search open PRs (page %d): %w", max(opts.Page, 1), explainTokenAccessError(err, repo))
+		}
+		dumpSearchResults(svc.debugSearch, query.String(), opts.Page, result)
+
+		for _, issue := range query.filter(result.Issues) {
+			pr := prInfoFromIssue(repo, issue)
+			pr.Description = redact(pr.Description, config.RedactRules)
+			pr.Open = true
+			openPRs = append(openPRs, pr)
+		}
+
+		if resp.NextPage == 0 {
+			return openPRs, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// writeOpenPRs writes the "In Progress" section for open PRs, kept apart from the merged
+// PRs so it is clear they are not finished contributions
+func writeOpenPRs(writer io.Writer, openPRs []PullRequestInfo, level int, config Config) error {
+	if len(openPRs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(writer, "%s In Progress\n\n", heading(level))
+	fmt.Fprintf(writer, "%d pull requests were still open at the time of this report. They are work in flight, not merged contributions.\n\n", len(openPRs))
+	return writeRepoGroups(writer, openPRs, level+1, config)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildOpenSearchQuery(t *testing.T) {
+	config := Config{
+		Username:  "someone",
+		SinceTime: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		UntilTime: time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "repo:owner/repo is:pr is:open author:someone created:<=2025-10-31 updated:>=2025-05-01",
+		buildOpenSearchQuery(NWO{Owner: "owner", Name: "repo"}, config).String())
+}
+
+func TestOutputOpenPRs(t *testing.T) {
+	config := Config{Usernames: []string{"alice", "bob"}, CombineUsers: true, OutputDir: "out", Repos: []string{"owner/repo"}, IncludeOpenPRs: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	merged := time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC)
+	mergedPR := PullRequestInfo{Repository: "owner/repo", Title: "Done", URL: "https://github.com/owner/repo/pull/1", MergedAt: &merged}
+	openPR := PullRequestInfo{Repository: "owner/repo", Title: "Still going", URL: "https://github.com/owner/repo/pull/2", Open: true}
+
+	t.Run("single user", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs([]PullRequestInfo{mergedPR}, []PullRequestInfo{openPR}, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		text := string(data)
+
+		assert.Contains(t, text, "Found 1 merged pull requests.")
+		assert.Contains(t, text, "\n# In Progress\n")
+		assert.Contains(t, text, "| **State** | Open |")
+		assert.Equal(t, 1, strings.Count(text, "| **Merged** |"), "open PRs have no merge row")
+		assert.Less(t, strings.Index(text, "[Done]"), strings.Index(text, "# In Progress"), "merged PRs come first")
+		assert.Greater(t, strings.Index(text, "[Still going]"), strings.Index(text, "# In Progress"))
+	})
+
+	t.Run("only open PRs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs(nil, []PullRequestInfo{openPR}, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), defaultNoPRsText)
+		assert.Contains(t, string(data), "[Still going]")
+	})
+
+	t.Run("team stats", func(t *testing.T) {
+		reports := []userReport{
+			{Username: "alice", PRs: []PullRequestInfo{mergedPR}, OpenPRs: []PullRequestInfo{openPR}},
+			{Username: "bob"},
+		}
+		path := filepath.Join(t.TempDir(), "team-report.md")
+		assert.NoError(t, outputTeamReport(reports, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		text := string(data)
+
+		assert.Contains(t, text, "Found 1 merged pull requests from 2 authors across 1 repositories.")
+		assert.Contains(t, text, "Another 1 pull requests are still open")
+		assert.Contains(t, text, "| alice | 1 | 1 | 1 |")
+		assert.Contains(t, text, "| bob | 0 | 0 | 0 |")
+		assert.Contains(t, text, "### In Progress")
+	})
+}
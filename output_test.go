@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputWriter(t *testing.T) {
+	t.Run("commit moves file into place", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.md")
+
+		writer, err := getOutputWriter(path)
+		if err != nil {
+			t.Fatalf("getOutputWriter failed: %v", err)
+		}
+		defer writer.Close()
+
+		fmt.Fprintf(writer, "hello\n")
+
+		// Nothing is visible at the destination until the write is committed
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+
+		assert.NoError(t, writer.Commit())
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello\n", string(data))
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary file should not be left behind")
+	})
+
+	t.Run("close without commit keeps previous contents", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "out.md")
+		if err := os.WriteFile(path, []byte("previous\n"), 0644); err != nil {
+			t.Fatalf("failed to write existing file: %v", err)
+		}
+
+		writer, err := getOutputWriter(path)
+		if err != nil {
+			t.Fatalf("getOutputWriter failed: %v", err)
+		}
+		fmt.Fprintf(writer, "partial")
+		assert.NoError(t, writer.Close())
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "previous\n", string(data))
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary file should be removed")
+	})
+}
+
+func TestOutputPRsEmptyStateText(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs(nil, nil, nil, path, config))
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), defaultNoPRsText)
+	})
+
+	t.Run("custom text", func(t *testing.T) {
+		custom := config
+		custom.NoPRsText = "_Nothing merged this period._"
+		custom.EmptyDescriptionText = "_Sin descripción._"
+
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs(nil, nil, nil, path, custom))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "_Nothing merged this period._")
+		assert.NotContains(t, string(data), defaultNoPRsText)
+
+		prs := []PullRequestInfo{{Repository: "owner/repo", Title: "Empty PR", URL: "https://github.com/owner/repo/pull/1"}}
+		assert.NoError(t, outputPRs(prs, nil, nil, path, custom))
+		data, err = os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "_Sin descripción._")
+		assert.NotContains(t, string(data), defaultEmptyDescriptionText)
+	})
+}
+
+func TestFormatSummary(t *testing.T) {
+	empty := ""
+	custom := "Review Packet: H1"
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name:     "default title",
+			expected: "# PR Summary\n\nDid things.\n",
+		},
+		{
+			name:     "prefix and suffix",
+			config:   Config{SummaryPrefix: "# My Review\n\nHello.\n", SummarySuffix: "-- Me\n", SummaryTitle: &empty},
+			expected: "# My Review\n\nHello.\n\nDid things.\n\n-- Me\n",
+		},
+		{
+			name:     "custom title with prefix",
+			config:   Config{SummaryPrefix: "Intro", SummaryTitle: &custom},
+			expected: "Intro\n\n# Review Packet: H1\n\nDid things.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatSummary("Did things.", tt.config))
+		})
+	}
+}
+
+func TestLoadConfigSummaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("Intro text\n"), 0644); err != nil {
+		t.Fatalf("failed to write prefix: %v", err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	yamlText := "username: someone\noutput_dir: out\nrepos: [owner/repo]\nsummary_prefix_file: intro.md\n"
+	if err := os.WriteFile(configFile, []byte(yamlText), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := loadConfig(configFile, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Intro text\n", config.SummaryPrefix, "prefix path is relative to the config file")
+
+	yamlText = "username: someone\noutput_dir: out\nrepos: [owner/repo]\nsummary_suffix_file: missing.md\n"
+	if err := os.WriteFile(configFile, []byte(yamlText), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	_, err = loadConfig(configFile, false)
+	assert.ErrorContains(t, err, "failed to read summary_suffix_file")
+}
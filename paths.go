@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// resolvePath expands a leading "~" to the user's home directory and makes a relative
+// path relative to baseDir. An empty path stays empty.
+func resolvePath(baseDir, path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand '%s': %w", path, err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// realPath resolves the symlinks in path. Parts of the path that don't exist yet (such
+// as an output directory that a run will create) are kept as they are.
+func realPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+// checkSafeOutputDir returns an error unless outputDir, with symlinks resolved, is root
+// or inside it. This is the -safe-paths check that keeps a symlinked or mistyped
+// output_dir from writing reports somewhere unexpected.
+func checkSafeOutputDir(outputDir, root string) error {
+	realOutput, err := realPath(outputDir)
+	if err != nil {
+		return fmt.Errorf("cannot resolve output_dir %s: %w", outputDir, err)
+	}
+	realRoot, err := realPath(root)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", root, err)
+	}
+
+	rel, err := filepath.Rel(realRoot, realOutput)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if realOutput != filepath.Clean(outputDir) {
+			return fmt.Errorf("output_dir %s resolves to %s, which is outside %s; refusing to write there with -safe-paths", outputDir, realOutput, realRoot)
+		}
+		return fmt.Errorf("output_dir %s is outside %s; refusing to write there with -safe-paths", outputDir, realRoot)
+	}
+	return nil
+}
+
+// makeTempOutputDir creates a fresh directory for a run's output (-temp-output). With
+// cleanup, the returned function removes the directory, and it is also removed if the
+// process is interrupted or terminated first; without cleanup the function does nothing.
+func makeTempOutputDir(cleanup bool) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "employment-justifier-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary output directory: %w", err)
+	}
+	if !cleanup {
+		return dir, func() {}, nil
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			os.RemoveAll(dir)
+			console.Errorf("Stopped by %v; removed temporary output directory %s", sig, dir)
+			os.Exit(exitInterrupted)
+		case <-done:
+		}
+	}()
+
+	remove := func() {
+		signal.Stop(signals)
+		close(done)
+		if err := os.RemoveAll(dir); err != nil {
+			console.Warnf("Failed to remove temporary output directory %s: %v", dir, err)
+		}
+	}
+	return dir, remove, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	base := filepath.Join(string(filepath.Separator), "configs")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "", expected: ""},
+		{path: "out", expected: filepath.Join(base, "out")},
+		{path: "./reports/../out", expected: filepath.Join(base, "out")},
+		{path: "/abs/out", expected: filepath.Clean("/abs/out")},
+		{path: "~", expected: home},
+		{path: "~/reviews/out", expected: filepath.Join(home, "reviews", "out")},
+		{path: "~other/out", expected: filepath.Join(base, "~other", "out")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resolved, err := resolvePath(base, tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestLoadConfigResolvesOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("username: someone\noutput_dir: out\nrepos: [owner/repo]\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := loadConfig(path, false)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "out"), config.OutputDir)
+}
+
+func TestCheckSafeOutputDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "real"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "inside")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	assert.NoError(t, checkSafeOutputDir(filepath.Join(root, "out"), root), "not created yet")
+	assert.NoError(t, checkSafeOutputDir(filepath.Join(root, "new", "nested"), root))
+	assert.NoError(t, checkSafeOutputDir(filepath.Join(root, "inside", "out"), root), "symlink within the root")
+	assert.NoError(t, checkSafeOutputDir(root, root))
+
+	err := checkSafeOutputDir(filepath.Join(root, "escape", "out"), root)
+	assert.ErrorContains(t, err, "which is outside")
+	assert.ErrorContains(t, err, "-safe-paths")
+
+	assert.ErrorContains(t, checkSafeOutputDir(filepath.Join(outside, "out"), root), "is outside")
+	assert.ErrorContains(t, checkSafeOutputDir(filepath.Join(root, ".."), root), "is outside")
+}
+
+func TestMakeTempOutputDir(t *testing.T) {
+	dir, remove, err := makeTempOutputDir(true)
+	assert.NoError(t, err)
+	assert.DirExists(t, dir)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "prs.md"), []byte("PRs"), 0644))
+	remove()
+	assert.NoDirExists(t, dir, "cleanup removes the directory and its contents")
+
+	dir, remove, err = makeTempOutputDir(false)
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	remove()
+	assert.DirExists(t, dir, "without cleanup the directory is kept")
+}
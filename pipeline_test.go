@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserPipeline runs a whole user through fetching, rendering, and summarizing against
+// a fake GitHub API and the echo summarizer, the way run does
+func TestUserPipeline(t *testing.T) {
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			w.Write([]byte(`{"total_count": 2, "items": [
+				{"number": 2, "title": "Second", "body": "Second body", "html_url": "https://github.com/owner/repo/pull/2", "created_at": "2025-05-03T10:00:00Z", "user": {"login": "someone"}},
+				{"number": 1, "title": "First", "body": "First body", "html_url": "https://github.com/owner/repo/pull/1", "created_at": "2025-05-01T10:00:00Z", "user": {"login": "someone"}}
+			]}`))
+		case "/repos/owner/repo/pulls/1":
+			w.Write([]byte(`{"number": 1, "body": "First PR description", "merged_at": "2025-05-02T10:00:00Z"}`))
+		case "/repos/owner/repo/pulls/2":
+			w.Write([]byte(`{"number": 2, "merged_at": "2025-05-04T10:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	config := Config{Username: "someone", Since: "2025-05-01", Until: "2025-05-31", OutputDir: t.TempDir(), Repos: []string{"owner/repo"}, Summarizer: summarizerEcho}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	summarizer, err := newSummarizer(config)
+	assert.NoError(t, err)
+
+	svc := newServices(context.Background(), summarizer, nil)
+	svc.client = client
+
+	report, err := fetchForUser(context.Background(), config, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Second", "First"}, titles(report.PRs))
+	assert.True(t, report.summarize)
+	assert.NoError(t, summarizeUsers(context.Background(), svc, []Config{config}, 1))
+
+	prs, err := os.ReadFile(filepath.Join(config.OutputDir, "prs.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(prs), "Found 2 merged pull requests.")
+	assert.Contains(t, string(prs), "First PR description")
+	assert.Contains(t, string(prs), "Second body")
+
+	snapshot, err := loadPRsJSON(filepath.Join(config.OutputDir, "prs.json"))
+	assert.NoError(t, err)
+	assert.Len(t, snapshot, 2)
+
+	summary, err := os.ReadFile(filepath.Join(config.OutputDir, "summary.md"))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^# PR Summary\n\nEcho summary of prs\.md: 2 PRs, prompt of \d+ characters, system prompt of 0 characters\.\n$`, string(summary))
+}
+
+// TestFetchForUserExplain checks that -explain lists every fetched PR when no filters are
+// configured, and still writes decisions.md when nothing was found
+func TestFetchForUserExplain(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{
+			name: "no filters",
+			search: `{"total_count": 1, "items": [
+				{"number": 1, "title": "First", "html_url": "https://github.com/owner/repo/pull/1", "created_at": "2025-05-01T10:00:00Z", "user": {"login": "someone"}}
+			]}`,
+			want: []string{"1 candidate pull requests, 1 included.", "| [First](https://github.com/owner/repo/pull/1) | owner/repo | author search | ✅ Included | no filters enabled |"},
+		},
+		{
+			name:   "no PRs",
+			search: `{"total_count": 0, "items": []}`,
+			want:   []string{"0 candidate pull requests, 0 included."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/search/issues":
+					w.Write([]byte(tt.search))
+				case "/repos/owner/repo/pulls/1":
+					w.Write([]byte(`{"number": 1, "merged_at": "2025-05-02T10:00:00Z"}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			config := Config{Username: "someone", Since: "2025-05-01", Until: "2025-05-31", OutputDir: t.TempDir(), Repos: []string{"owner/repo"}, Summarizer: summarizerEcho}
+			if err := config.Parse(); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			config.Explain = true
+			summarizer, err := newSummarizer(config)
+			assert.NoError(t, err)
+
+			svc := newServices(context.Background(), summarizer, nil)
+			svc.client = client
+
+			_, err = fetchForUser(context.Background(), config, svc)
+			assert.NoError(t, err)
+
+			decisions, err := os.ReadFile(filepath.Join(config.OutputDir, "decisions.md"))
+			assert.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(decisions), want)
+			}
+		})
+	}
+}
+
+// TestFetchForUserWithoutAuthoredPRs checks that a user with no authored PRs in the window
+// still gets a report, covering whatever the later passes find
+func TestFetchForUserWithoutAuthoredPRs(t *testing.T) {
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			switch query := r.URL.Query().Get("q"); {
+			case strings.Contains(query, "-author:someone"):
+				w.Write([]byte(`{"total_count": 1, "items": [
+					{"number": 5, "title": "Pairing session", "html_url": "https://github.com/owner/repo/pull/5", "created_at": "2025-05-06T10:00:00Z", "user": {"login": "other"}}
+				]}`))
+			case strings.Contains(query, "is:open"):
+				w.Write([]byte(`{"total_count": 1, "items": [
+					{"number": 6, "title": "Draft migration", "html_url": "https://github.com/owner/repo/pull/6", "created_at": "2025-05-20T10:00:00Z", "user": {"login": "someone"}}
+				]}`))
+			default:
+				w.Write([]byte(`{"total_count": 0, "items": []}`))
+			}
+		case "/repos/owner/repo/pulls/5/commits":
+			w.Write([]byte(`[{"commit": {"message": "Pair\n\nCo-authored-by: Someone <someone@users.noreply.github.com>"}}]`))
+		case "/repos/owner/repo/pulls/5":
+			w.Write([]byte(`{"number": 5, "merged_at": "2025-05-07T10:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	tests := []struct {
+		name      string
+		configure func(config *Config)
+		want      []string
+		summarize bool
+	}{
+		{
+			name:      "co-authored PRs",
+			configure: func(config *Config) { config.IncludeCoAuthored = true },
+			want:      []string{"Found 1 merged pull requests.", "Pairing session"},
+			summarize: true,
+		},
+		{
+			name:      "open PRs",
+			configure: func(config *Config) { config.IncludeOpenPRs = true },
+			want:      []string{"Found 0 merged pull requests.", "# In Progress\n\n1 pull requests were still open", "Draft migration"},
+			summarize: true,
+		},
+		{
+			name:      "nothing at all",
+			configure: func(config *Config) { config.NoPRsText = "_Nothing merged this month._" },
+			want:      []string{"Found 0 merged pull requests.\n\n_Nothing merged this month._\n"},
+			summarize: false,
+		},
+		{
+			name: "empty repositories",
+			configure: func(config *Config) {
+				config.ShowEmptyRepos = true
+				config.Repos = []string{"owner/repo", "owner/other"}
+			},
+			want:      []string{"*No contributions to owner/repo in this period.*", "*No contributions to owner/other in this period.*"},
+			summarize: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Username: "someone", Since: "2025-05-01", Until: "2025-05-31", OutputDir: t.TempDir(), Repos: []string{"owner/repo"}, Summarizer: summarizerEcho}
+			tt.configure(&config)
+			if err := config.Parse(); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			svc := newServices(context.Background(), echoSummarizer{}, nil)
+			svc.client = client
+
+			report, err := fetchForUser(context.Background(), config, svc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.summarize, report.summarize)
+
+			prs, err := os.ReadFile(filepath.Join(config.OutputDir, "prs.md"))
+			assert.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(prs), want)
+			}
+			assert.FileExists(t, filepath.Join(config.OutputDir, "prs.json"))
+		})
+	}
+}
+
+func TestSummarizeUsers(t *testing.T) {
+	dir := t.TempDir()
+	var userConfigs []Config
+	for _, username := range []string{"alice", "bob", "carol", "dave"} {
+		config := Config{Username: username, OutputDir: filepath.Join(dir, username), Summarizer: summarizerEcho}
+		assert.NoError(t, os.MkdirAll(config.OutputDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(config.OutputDir, "prs.md"), []byte("PRs\n"), 0644))
+		userConfigs = append(userConfigs, config)
+	}
+
+	t.Run("at most workers users at once", func(t *testing.T) {
+		backend := &inFlightCounter{release: make(chan struct{})}
+		svc := newServices(context.Background(), newLimitedSummarizer(backend.summarizer(), 4), nil)
+
+		done := make(chan error)
+		go func() { done <- summarizeUsers(context.Background(), svc, userConfigs, 2) }()
+		assert.Eventually(t, func() bool {
+			backend.mu.Lock()
+			defer backend.mu.Unlock()
+			return backend.inFlight == 2
+		}, time.Second, time.Millisecond)
+		for range userConfigs {
+			backend.release <- struct{}{}
+		}
+		assert.NoError(t, <-done)
+		assert.Equal(t, 2, backend.maxInFlight)
+		for _, config := range userConfigs {
+			assert.FileExists(t, filepath.Join(config.OutputDir, "summary.md"))
+		}
+	})
+
+	t.Run("every user is attempted", func(t *testing.T) {
+		for _, config := range userConfigs {
+			os.Remove(filepath.Join(config.OutputDir, "summary.md"))
+		}
+		svc := newServices(context.Background(), funcSummarizer(func(req SummaryRequest) (string, error) {
+			if strings.Contains(req.InputFile, "bob") {
+				return "", errors.New("summarizer crashed")
+			}
+			return "summary", nil
+		}), nil)
+
+		err := summarizeUsers(context.Background(), svc, userConfigs, 1)
+		assert.ErrorContains(t, err, "failed to summarize PRs of user bob")
+		assert.ErrorContains(t, err, "summarizer crashed")
+		assert.Equal(t, exitSummarizer, exitCodeFor(err))
+		assert.NoFileExists(t, filepath.Join(dir, "bob", "summary.md"))
+		assert.FileExists(t, filepath.Join(dir, "dave", "summary.md"), "users after the failure are still summarized")
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// defaultCacheFreshnessDays is how recently a PR must have been merged for its cached
+// details to be fetched again anyway
+const defaultCacheFreshnessDays = 7
+
+// prDetails is what is fetched for each PR beyond its search result
+type prDetails struct {
+	Body           string     `json:"body"`
+	MergedAt       *time.Time `json:"merged_at,omitempty"`
+	Comments       int        `json:"comments"`
+	ReviewComments int        `json:"review_comments"`
+	BaseRef        string     `json:"base_ref,omitempty"`
+	HeadRef        string     `json:"head_ref,omitempty"`
+	DefaultBranch  string     `json:"default_branch,omitempty"`
+	MergeCommitSHA string     `json:"merge_commit_sha,omitempty"`
+}
+
+// prDetailsFromPR extracts the details we keep from a fetched PR
+func prDetailsFromPR(pr *github.PullRequest) prDetails {
+	details := prDetails{
+		Body:           pr.GetBody(),
+		Comments:       pr.GetComments(),
+		ReviewComments: pr.GetReviewComments(),
+		BaseRef:        pr.GetBase().GetRef(),
+		HeadRef:        pr.GetHead().GetRef(),
+		DefaultBranch:  pr.GetBase().GetRepo().GetDefaultBranch(),
+	}
+	if pr.MergedAt != nil {
+		mergedAt := pr.GetMergedAt().Time
+		details.MergedAt = &mergedAt
+		// Before merging, this is a test merge commit that may not exist anymore
+		details.MergeCommitSHA = pr.GetMergeCommitSHA()
+	}
+	return details
+}
+
+// apply copies the details into prInfo
+func (d prDetails) apply(prInfo *PullRequestInfo) {
+	// The PR body is more detailed than the issue body
+	if d.Body != "" {
+		prInfo.Description = d.Body
+	}
+	prInfo.MergedAt = d.MergedAt
+	prInfo.Comments = d.Comments
+	prInfo.ReviewComments = d.ReviewComments
+	prInfo.BaseRef = d.BaseRef
+	prInfo.HeadRef = d.HeadRef
+	prInfo.DefaultBranch = d.DefaultBranch
+	prInfo.MergeCommitSHA = d.MergeCommitSHA
+}
+
+// prCache stores PR details on disk so that later runs over the same window don't fetch
+// every PR again. Details of recently merged PRs may still change (descriptions get
+// edited, comments keep coming), so they are only served from the cache once the PR was
+// merged longer than freshness ago.
+type prCache struct {
+	dir       string
+	freshness time.Duration
+	now       func() time.Time
+}
+
+// newPRCache creates a PR cache for host in the user cache directory
+func newPRCache(host string, freshness time.Duration) (*prCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return &prCache{dir: filepath.Join(dir, "employment-justifier", "prs", host), freshness: freshness, now: time.Now}, nil
+}
+
+// path returns the cache file for a PR
+func (c *prCache) path(repo NWO, number int) string {
+	return filepath.Join(c.dir, repo.Owner, repo.Name, fmt.Sprintf("%d.json", number))
+}
+
+// Load returns the cached details of a PR, if there are any and they are old enough to trust
+func (c *prCache) Load(repo NWO, number int) (prDetails, bool) {
+	data, err := os.ReadFile(c.path(repo, number))
+	if err != nil {
+		return prDetails{}, false
+	}
+	var details prDetails
+	if err := json.Unmarshal(data, &details); err != nil {
+		return prDetails{}, false
+	}
+	if details.MergedAt == nil || c.now().Sub(*details.MergedAt) < c.freshness {
+		return prDetails{}, false
+	}
+	return details, true
+}
+
+// Store saves the details of a PR
+func (c *prCache) Store(repo NWO, number int, details prDetails) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	path := c.path(repo, number)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRCache(t *testing.T) {
+	now := time.Date(2025, 5, 31, 12, 0, 0, 0, time.UTC)
+	cache := &prCache{dir: t.TempDir(), freshness: 7 * 24 * time.Hour, now: func() time.Time { return now }}
+	repo := NWO{Owner: "owner", Name: "repo"}
+
+	old := now.Add(-30 * 24 * time.Hour)
+	recent := now.Add(-2 * 24 * time.Hour)
+
+	_, ok := cache.Load(repo, 1)
+	assert.False(t, ok, "nothing cached yet")
+
+	details := prDetails{Body: "old", MergedAt: &old, Comments: 3}
+	assert.NoError(t, cache.Store(repo, 1, details))
+	loaded, ok := cache.Load(repo, 1)
+	assert.True(t, ok)
+	assert.Equal(t, "old", loaded.Body)
+	assert.Equal(t, 3, loaded.Comments)
+	assert.True(t, old.Equal(*loaded.MergedAt))
+
+	assert.NoError(t, cache.Store(repo, 2, prDetails{Body: "recent", MergedAt: &recent}))
+	_, ok = cache.Load(repo, 2)
+	assert.False(t, ok, "merged within the freshness window")
+
+	assert.NoError(t, cache.Store(repo, 3, prDetails{Body: "unmerged"}))
+	_, ok = cache.Load(repo, 3)
+	assert.False(t, ok, "unknown merge time")
+
+	cache.freshness = 0
+	_, ok = cache.Load(repo, 2)
+	assert.True(t, ok, "freshness of zero trusts every merged PR")
+}
+
+func TestGetPRInfoUsesCache(t *testing.T) {
+	fetches := 0
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte(`{"number": 1, "body": "Fetched body", "merged_at": "2025-01-02T10:00:00Z", "comments": 4}`))
+	}))
+
+	cache := &prCache{dir: t.TempDir(), freshness: 7 * 24 * time.Hour, now: time.Now}
+	repo := NWO{Owner: "owner", Name: "repo"}
+	issue := &github.Issue{Number: github.Int(1), Title: github.String("Title"), Body: github.String("Issue body")}
+
+	first := getPRInfo(context.Background(), client, cache, repo, issue, Config{})
+	second := getPRInfo(context.Background(), client, cache, repo, issue, Config{})
+	assert.Equal(t, 1, fetches, "second lookup is served from the cache")
+	assert.Equal(t, first, second)
+	assert.Equal(t, "Fetched body", second.Description)
+	assert.Equal(t, 4, second.Comments)
+}
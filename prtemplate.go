@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// prTemplateData is what a pr_template is executed against, for one PR
+type prTemplateData struct {
+	// Heading is the Markdown heading prefix for the PR, e.g. "###", which depends on
+	// where the PR appears in the report
+	Heading    string
+	Title      string
+	URL        string
+	Repository string
+	Author     string
+	CreatedAt  time.Time
+	MergedAt   *time.Time
+	// Created and Merged are CreatedAt and MergedAt formatted as in the default layout;
+	// Merged is empty if the merge time is unknown
+	Created string
+	Merged  string
+	// Description is the extracted (and possibly truncated) description, or
+	// empty_description_text if there is none
+	Description    string
+	HasDescription bool
+	Truncated      bool
+	CoAuthored     bool
+	RevertedBy     string
+	Open           bool
+	ImpactTag      string
+}
+
+// parsePRTemplate parses the pr_template setting
+func parsePRTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("pr_template").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pr_template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// newPRTemplateData collects the template data for a PR rendered at the given heading level
+func newPRTemplateData(pr PullRequestInfo, level int, config Config) prTemplateData {
+	data := prTemplateData{
+		Heading:    heading(level),
+		Title:      pr.Title,
+		URL:        pr.URL,
+		Repository: pr.Repository,
+		Author:     pr.Author,
+		CreatedAt:  pr.CreatedAt,
+		MergedAt:   pr.MergedAt,
+		Created:    pr.CreatedAt.Format("2006-01-02 15:04:05"),
+		CoAuthored: pr.CoAuthored,
+		RevertedBy: pr.RevertedBy,
+		Open:       pr.Open,
+		ImpactTag:  pr.ImpactTag,
+	}
+	if pr.MergedAt != nil {
+		data.Merged = pr.MergedAt.Format("2006-01-02 15:04:05")
+	}
+
+	if strings.TrimSpace(pr.Description) != "" {
+		data.Description, data.Truncated = extractDescription(pr, config)
+		data.HasDescription = true
+	} else {
+		data.Description = config.EmptyDescriptionText
+	}
+	return data
+}
+
+// writePRFromTemplate renders one PR with the configured pr_template
+func writePRFromTemplate(writer io.Writer, pr PullRequestInfo, level int, config Config) error {
+	if err := config.PRTemplateParsed.Execute(writer, newPRTemplateData(pr, level, config)); err != nil {
+		return fmt.Errorf("failed to render pr_template for %s: %w", pr.URL, err)
+	}
+	return nil
+}
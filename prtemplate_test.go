@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRTemplate(t *testing.T) {
+	merged := time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC)
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", Title: "With description", Description: "Did a thing", URL: "https://github.com/owner/repo/pull/1", CreatedAt: merged.Add(-time.Hour), MergedAt: &merged},
+		{Repository: "owner/repo", Title: "Without description", URL: "https://github.com/owner/repo/pull/2"},
+	}
+
+	t.Run("bullets", func(t *testing.T) {
+		config := Config{
+			Username:   "someone",
+			OutputDir:  "out",
+			Repos:      []string{"owner/repo"},
+			PRTemplate: "{{.Heading}} {{.Title}}\n\n- Merged: {{if .Merged}}{{.Merged}}{{else}}unknown{{end}}\n- {{.URL}}\n\n{{.Description}}\n\n",
+		}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs(prs, nil, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		text := string(data)
+
+		assert.Contains(t, text, "## owner/repo\n\n### With description\n\n- Merged: 2025-05-02 10:00:00\n- https://github.com/owner/repo/pull/1\n\nDid a thing\n\n---\n\n")
+		assert.Contains(t, text, "### Without description\n\n- Merged: unknown\n- https://github.com/owner/repo/pull/2\n\n"+defaultEmptyDescriptionText)
+		assert.NotContains(t, text, "| Field | Value |")
+	})
+
+	t.Run("heading follows nesting", func(t *testing.T) {
+		config := Config{Usernames: []string{"alice", "bob"}, CombineUsers: true, OutputDir: "out", Repos: []string{"owner/repo"}, PRTemplate: "{{.Heading}} {{.Title}}\n\n"}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+
+		path := filepath.Join(t.TempDir(), "team-report.md")
+		assert.NoError(t, outputTeamReport([]userReport{{Username: "alice", PRs: prs[:1]}, {Username: "bob"}}, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "#### With description\n\n")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, PRTemplate: "{{.Title"}
+		assert.ErrorContains(t, config.Parse(), "invalid pr_template")
+	})
+
+	t.Run("execution error", func(t *testing.T) {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, PRTemplate: "{{.NoSuchField}}"}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		err := outputPRs(prs, nil, nil, filepath.Join(t.TempDir(), "prs.md"), config)
+		assert.ErrorContains(t, err, "failed to render pr_template for https://github.com/owner/repo/pull/1")
+	})
+}
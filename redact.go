@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// redactedText replaces every match of a redaction rule
+const redactedText = "[REDACTED]"
+
+// defaultRedactPatterns match things that look like credentials. They are used with
+// redact_descriptions, in addition to any redact_patterns.
+var defaultRedactPatterns = []string{
+	// GitHub tokens
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	`\bgithub_pat_[A-Za-z0-9_]{22,}\b`,
+	// AWS access key IDs
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	// Google API keys
+	`\bAIza[0-9A-Za-z_-]{35}\b`,
+	// Slack tokens
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`,
+	// JSON Web Tokens
+	`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`,
+	// PEM private keys
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	// Assignments to secret-sounding names, e.g. "api_key: abcd1234efgh"
+	`(?i)\b(?:api[_-]?key|secret|password|passwd|access[_-]?token)\b\s*[:=]\s*["']?[^\s"']{8,}["']?`,
+}
+
+// compileRedactRules compiles the user's redaction patterns, preceded by the defaults
+// if withDefaults is set
+func compileRedactRules(patterns []string, withDefaults bool) ([]*regexp.Regexp, error) {
+	var rules []*regexp.Regexp
+	if withDefaults {
+		for _, pattern := range defaultRedactPatterns {
+			rules = append(rules, regexp.MustCompile(pattern))
+		}
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("redact_patterns cannot contain an empty pattern")
+		}
+		rule, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact_patterns entry '%s': %w", pattern, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// redact replaces every match of the rules in text with [REDACTED]
+func redact(text string, rules []*regexp.Regexp) string {
+	for _, rule := range rules {
+		text = rule.ReplaceAllLiteralString(text, redactedText)
+	}
+	return text
+}
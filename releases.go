@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// resolveReleaseTags sets SinceTime and UntilTime from the since_tag and until_tag
+// releases of config.ReleaseRepo
+func resolveReleaseTags(ctx context.Context, client *github.Client, config *Config) error {
+	if config.SinceTag != "" {
+		since, err := getReleaseDate(ctx, client, config.ReleaseRepoNWO, config.SinceTag)
+		if err != nil {
+			return err
+		}
+		config.SinceTime = since
+	}
+	if config.UntilTag != "" {
+		until, err := getReleaseDate(ctx, client, config.ReleaseRepoNWO, config.UntilTag)
+		if err != nil {
+			return err
+		}
+		config.UntilTime = until
+	}
+
+	if !config.SinceTime.Before(config.UntilTime) {
+		return fmt.Errorf("start of date window (%s) is not before its end (%s)",
+			config.SinceTime.Format(time.RFC3339), config.UntilTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// getReleaseDate looks up the release for tag and returns when it was published
+func getReleaseDate(ctx context.Context, client *github.Client, repo NWO, tag string) (time.Time, error) {
+	release, _, err := client.Repositories.GetReleaseByTag(ctx, repo.Owner, repo.Name, tag)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get release %s of %s/%s: %w", tag, repo.Owner, repo.Name, err)
+	}
+	return releaseDate(release)
+}
+
+// releaseDate returns when a release was published, or for an unpublished (draft)
+// release, when it was created
+func releaseDate(release *github.RepositoryRelease) (time.Time, error) {
+	if release.PublishedAt != nil {
+		return release.PublishedAt.Time, nil
+	}
+	if release.CreatedAt != nil {
+		return release.CreatedAt.Time, nil
+	}
+	return time.Time{}, fmt.Errorf("release %s has no publish or creation date", release.GetTagName())
+}
+
+// releaseWindowFilter keeps PRs merged after since and no later than until. The search
+// query only has day granularity, so this trims PRs merged on the release days themselves
+// but outside the releases.
+func releaseWindowFilter(since, until time.Time) prFilter {
+	return prFilter{
+		Name: "release-window",
+		Keep: func(pr PullRequestInfo) (bool, string) {
+			if pr.MergedAt == nil {
+				return true, "merge time unknown"
+			}
+			if !pr.MergedAt.After(since) {
+				return false, "merged before the start release"
+			}
+			if pr.MergedAt.After(until) {
+				return false, "merged after the end release"
+			}
+			return true, "merged between the releases"
+		},
+	}
+}
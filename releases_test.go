@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseTagConfig(t *testing.T) {
+	base := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{
+			name:   "both tags",
+			modify: func(c *Config) { c.ReleaseRepo = "owner/repo"; c.SinceTag = "v2.3"; c.UntilTag = "v2.4" },
+		},
+		{
+			name:   "since tag until now",
+			modify: func(c *Config) { c.ReleaseRepo = "owner/repo"; c.SinceTag = "v2.3" },
+		},
+		{
+			name:    "missing release_repo",
+			modify:  func(c *Config) { c.SinceTag = "v2.3" },
+			wantErr: "release_repo is required",
+		},
+		{
+			name:    "invalid release_repo",
+			modify:  func(c *Config) { c.ReleaseRepo = "repo"; c.SinceTag = "v2.3" },
+			wantErr: "invalid release_repo",
+		},
+		{
+			name:    "since and since_tag",
+			modify:  func(c *Config) { c.ReleaseRepo = "owner/repo"; c.SinceTag = "v2.3"; c.Since = "2025-01-01" },
+			wantErr: "since and since_tag cannot both be set",
+		},
+		{
+			name:    "until tag without start",
+			modify:  func(c *Config) { c.ReleaseRepo = "owner/repo"; c.UntilTag = "v2.4" },
+			wantErr: "since or since_tag is required",
+		},
+		{
+			name:    "release_repo without tags",
+			modify:  func(c *Config) { c.ReleaseRepo = "owner/repo" },
+			wantErr: "release_repo requires since_tag or until_tag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base
+			tt.modify(&config)
+			err := config.Parse()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveReleaseTags(t *testing.T) {
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases/tags/v2.3":
+			w.Write([]byte(`{"tag_name":"v2.3","created_at":"2025-03-01T09:00:00Z","published_at":"2025-03-01T12:00:00Z"}`))
+		case "/repos/owner/repo/releases/tags/v2.4":
+			w.Write([]byte(`{"tag_name":"v2.4","created_at":"2025-04-01T09:00:00Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	config := Config{ReleaseRepoNWO: NWO{Owner: "owner", Name: "repo"}, SinceTag: "v2.3", UntilTag: "v2.4"}
+	assert.NoError(t, resolveReleaseTags(context.Background(), client, &config))
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), config.SinceTime.UTC(), "uses the publish date")
+	assert.Equal(t, time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC), config.UntilTime.UTC(), "falls back to the creation date")
+
+	reversed := Config{ReleaseRepoNWO: NWO{Owner: "owner", Name: "repo"}, SinceTag: "v2.4", UntilTag: "v2.3"}
+	assert.ErrorContains(t, resolveReleaseTags(context.Background(), client, &reversed), "is not before its end")
+
+	missing := Config{ReleaseRepoNWO: NWO{Owner: "owner", Name: "repo"}, SinceTag: "v9.9"}
+	assert.ErrorContains(t, resolveReleaseTags(context.Background(), client, &missing), "failed to get release v9.9 of owner/repo")
+}
+
+func TestReleaseWindowFilter(t *testing.T) {
+	since := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+
+	prs := []PullRequestInfo{
+		{Title: "same day, before release", MergedAt: at(since.Add(-time.Hour))},
+		{Title: "inside", MergedAt: at(since.Add(24 * time.Hour))},
+		{Title: "at end release", MergedAt: at(until)},
+		{Title: "same day, after release", MergedAt: at(until.Add(time.Hour))},
+		{Title: "unknown"},
+	}
+
+	kept := applyFilters(prs, []prFilter{releaseWindowFilter(since, until)}, nil)
+	assert.Equal(t, []string{"inside", "at end release", "unknown"}, titles(kept))
+
+	t.Run("until date", func(t *testing.T) {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, ReleaseRepo: "owner/repo", SinceTag: "v2.3", Until: "2025-04-01"}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		config.SinceTime = since
+		assert.Equal(t, "merged:2025-03-01..2025-04-01", windowQualifier(config).Text)
+
+		prs := []PullRequestInfo{
+			{Title: "late on the until date", MergedAt: at(time.Date(2025, 4, 1, 23, 30, 0, 0, time.UTC))},
+			{Title: "the day after", MergedAt: at(time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC))},
+		}
+		kept := applyFilters(prs, []prFilter{releaseWindowFilter(config.SinceTime, config.UntilTime)}, nil)
+		assert.Equal(t, []string{"late on the until date"}, titles(kept))
+	})
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// Orders for the repository sections of a report
+	repoOrderAsConfigured = "as-configured"
+	repoOrderAlpha        = "alpha"
+	repoOrderVolume       = "volume"
+
+	// Heading of the section that collects repos below min_prs_per_repo_section
+	miscellaneousSection = "Miscellaneous"
+)
+
+// repoGroup holds the PRs for one repository, in report order. A collapsed group holds
+// the PRs of several low-activity repositories under miscellaneousSection.
+type repoGroup struct {
+	Repository string
+	PRs        []PullRequestInfo
+	Collapsed  bool
+}
+
+// groupByRepo groups PRs by repository, keeping repositories in the order they are first seen
+func groupByRepo(prs []PullRequestInfo) []repoGroup {
+	var groups []repoGroup
+	index := make(map[string]int)
+	for _, pr := range prs {
+		i, ok := index[pr.Repository]
+		if !ok {
+			i = len(groups)
+			index[pr.Repository] = i
+			groups = append(groups, repoGroup{Repository: pr.Repository})
+		}
+		groups[i].PRs = append(groups[i].PRs, pr)
+	}
+	return groups
+}
+
+// orderRepoGroups returns the PRs grouped by repository, with the groups in the order
+// given by config.RepoOrder and low-activity repositories collapsed at the end
+func orderRepoGroups(prs []PullRequestInfo, config Config) []repoGroup {
+	groups := groupByRepo(prs)
+
+	switch config.RepoOrder {
+	case repoOrderAlpha:
+		sort.SliceStable(groups, func(i, j int) bool {
+			return groups[i].Repository < groups[j].Repository
+		})
+	case repoOrderVolume:
+		sort.SliceStable(groups, func(i, j int) bool {
+			if len(groups[i].PRs) != len(groups[j].PRs) {
+				return len(groups[i].PRs) > len(groups[j].PRs)
+			}
+			return groups[i].Repository < groups[j].Repository
+		})
+	default:
+		// As configured: the order of repos, with any others after them as first seen
+		position := make(map[string]int, len(config.Repos))
+		for i, repo := range config.ReposNWO {
+			position[fmt.Sprintf("%s/%s", repo.Owner, repo.Name)] = i
+		}
+		rank := func(repository string) int {
+			if i, ok := position[repository]; ok {
+				return i
+			}
+			return len(position)
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			return rank(groups[i].Repository) < rank(groups[j].Repository)
+		})
+	}
+
+	return collapseSmallGroups(groups, config.MinPRsPerRepoSection)
+}
+
+// collapseSmallGroups moves the PRs of every group with fewer than minPRs PRs into a
+// single collapsed group at the end, keeping their order. That includes a lone small
+// group, whose PRs still show their repository in the collapsed section.
+func collapseSmallGroups(groups []repoGroup, minPRs int) []repoGroup {
+	small := 0
+	for _, group := range groups {
+		if len(group.PRs) < minPRs {
+			small++
+		}
+	}
+	if small == 0 {
+		return groups
+	}
+
+	var kept []repoGroup
+	misc := repoGroup{Repository: miscellaneousSection, Collapsed: true}
+	for _, group := range groups {
+		if len(group.PRs) < minPRs {
+			misc.PRs = append(misc.PRs, group.PRs...)
+		} else {
+			kept = append(kept, group)
+		}
+	}
+	return append(kept, misc)
+}
+
+// emptyRepos returns the configured repositories without any of the PRs, in config order
+func emptyRepos(prs []PullRequestInfo, config Config) []string {
+	found := make(map[string]bool)
+	for _, pr := range prs {
+		found[pr.Repository] = true
+	}
+
+	var empty []string
+	for _, repo := range config.ReposNWO {
+		repository := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+		if !found[repository] {
+			empty = append(empty, repository)
+		}
+	}
+	return empty
+}
+
+// writeEmptyRepos writes a stub section for each configured repository without PRs, so
+// that readers can see it was checked rather than forgotten
+func writeEmptyRepos(writer io.Writer, prs []PullRequestInfo, level int, config Config) {
+	for _, repository := range emptyRepos(prs, config) {
+		fmt.Fprintf(writer, "%s %s\n\n", heading(level), repoHeading(repoGroup{Repository: repository}, config))
+		fmt.Fprintf(writer, "*No contributions to %s in this period.*\n\n", repoLabel(repository, config))
+	}
+}
+
+// repoHeading returns the Markdown heading text for a group: the repository's display
+// name linked to the repository if it has one, and otherwise just its owner/name
+func repoHeading(group repoGroup, config Config) string {
+	name, ok := config.RepoDisplayNames[group.Repository]
+	if group.Collapsed || !ok {
+		return group.Repository
+	}
+	return fmt.Sprintf("[%s](%s)", name, repoURL(group))
+}
+
+// repoLabel returns the display name of a repository followed by its owner/name, or just
+// the owner/name if it has no display name
+func repoLabel(repository string, config Config) string {
+	if name, ok := config.RepoDisplayNames[repository]; ok {
+		return fmt.Sprintf("%s (%s)", name, repository)
+	}
+	return repository
+}
+
+// repoURL returns the web URL of a group's repository, taken from its PRs' URLs so that
+// it points at the right GitHub host
+func repoURL(group repoGroup) string {
+	for _, pr := range group.PRs {
+		if i := strings.LastIndex(pr.URL, "/pull/"); i > 0 {
+			return pr.URL[:i]
+		}
+	}
+	return fmt.Sprintf("https://%s/%s", githubHost(), group.Repository)
+}
+
+// extractDescription returns the part of a PR's description to render, extracted for its
+// repository and limited to MaxDescriptionChars, and whether it was truncated
+func extractDescription(pr PullRequestInfo, config Config) (string, bool) {
+	text := getRepositorySpecificDescription(pr.Repository, pr.Description, config.ExtractorRules)
+	return truncateAtWordBoundary(text, config.MaxDescriptionChars)
+}
+
+// truncateAtWordBoundary shortens text to at most maxChars characters (runes, not bytes),
+// cutting at the last whitespace before the limit when there is one. It reports whether
+// the text was shortened. A maxChars of zero or less means no limit.
+func truncateAtWordBoundary(text string, maxChars int) (string, bool) {
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text, false
+	}
+
+	cut := maxChars
+	// Back up to a word boundary unless the limit already falls on one
+	if !unicode.IsSpace(runes[cut]) {
+		for i := cut - 1; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace), true
+}
+
+// prLeadTime returns how long a PR took from creation to merge, or false if its merge
+// time is unknown
+func prLeadTime(pr PullRequestInfo) (time.Duration, bool) {
+	if pr.MergedAt == nil {
+		return 0, false
+	}
+	return pr.MergedAt.Sub(pr.CreatedAt), true
+}
+
+// averageLeadTime returns the mean lead time of the PRs with a known merge time, and how
+// many of them there are
+func averageLeadTime(prs []PullRequestInfo) (time.Duration, int) {
+	var total time.Duration
+	known := 0
+	for _, pr := range prs {
+		if leadTime, ok := prLeadTime(pr); ok {
+			total += leadTime
+			known++
+		}
+	}
+	if known == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(known), known
+}
+
+// formatDuration formats a duration for reports in its two largest units, e.g. "2d 4h"
+// or "3h 15m", rounded down to the minute
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case minutes > 0 && hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
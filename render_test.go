@@ -0,0 +1,356 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateAtWordBoundary(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		maxChars          int
+		expected          string
+		expectedTruncated bool
+	}{
+		{
+			name:     "no limit",
+			text:     "Some long description",
+			maxChars: 0,
+			expected: "Some long description",
+		},
+		{
+			name:     "shorter than limit",
+			text:     "Short",
+			maxChars: 10,
+			expected: "Short",
+		},
+		{
+			name:     "exactly at limit",
+			text:     "Exactly ten",
+			maxChars: 11,
+			expected: "Exactly ten",
+		},
+		{
+			name:              "cuts back to word boundary",
+			text:              "The quick brown fox jumps",
+			maxChars:          12,
+			expected:          "The quick",
+			expectedTruncated: true,
+		},
+		{
+			name:              "limit falls on whitespace",
+			text:              "The quick brown fox",
+			maxChars:          9,
+			expected:          "The quick",
+			expectedTruncated: true,
+		},
+		{
+			name:              "single long word is cut hard",
+			text:              "Supercalifragilisticexpialidocious",
+			maxChars:          5,
+			expected:          "Super",
+			expectedTruncated: true,
+		},
+		{
+			name:              "trailing newlines are trimmed",
+			text:              "First paragraph.\n\nSecond paragraph.",
+			maxChars:          18,
+			expected:          "First paragraph.",
+			expectedTruncated: true,
+		},
+		{
+			name:              "multibyte characters count as one",
+			text:              "日本語のテキスト です とても長い",
+			maxChars:          10,
+			expected:          "日本語のテキスト",
+			expectedTruncated: true,
+		},
+		{
+			name:              "multibyte hard cut stays valid UTF-8",
+			text:              "🎉🎉🎉🎉🎉🎉",
+			maxChars:          3,
+			expected:          "🎉🎉🎉",
+			expectedTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, truncated := truncateAtWordBoundary(tt.text, tt.maxChars)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.expectedTruncated, truncated)
+			assert.True(t, utf8.ValidString(result))
+		})
+	}
+}
+
+func TestOrderRepoGroups(t *testing.T) {
+	pr := func(repo string) PullRequestInfo { return PullRequestInfo{Repository: repo} }
+	prs := []PullRequestInfo{
+		pr("org/zeta"), pr("org/beta"), pr("org/zeta"), pr("org/alpha"), pr("org/beta"), pr("other/extra"),
+	}
+
+	tests := []struct {
+		order    string
+		expected []string
+	}{
+		{order: repoOrderAsConfigured, expected: []string{"org/zeta", "org/alpha", "org/beta", "other/extra"}},
+		{order: repoOrderAlpha, expected: []string{"org/alpha", "org/beta", "org/zeta", "other/extra"}},
+		{order: repoOrderVolume, expected: []string{"org/beta", "org/zeta", "org/alpha", "other/extra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			config := Config{Username: "someone", OutputDir: "out", Repos: []string{"org/zeta", "org/alpha", "org/beta"}, RepoOrder: tt.order}
+			if err := config.Parse(); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			var repos []string
+			for _, group := range orderRepoGroups(prs, config) {
+				repos = append(repos, group.Repository)
+			}
+			assert.Equal(t, tt.expected, repos)
+		})
+	}
+}
+
+func TestCollapseSmallGroups(t *testing.T) {
+	pr := func(repo, title string) PullRequestInfo { return PullRequestInfo{Repository: repo, Title: title} }
+	groups := groupByRepo([]PullRequestInfo{
+		pr("org/big", "one"), pr("org/small", "two"), pr("org/big", "three"), pr("org/tiny", "four"),
+	})
+
+	tests := []struct {
+		name     string
+		groups   []repoGroup
+		minPRs   int
+		expected []repoGroup
+	}{
+		{name: "disabled", groups: groups, minPRs: 0, expected: groups},
+		{name: "lone small repo is collapsed too", groups: groups[:2], minPRs: 2, expected: []repoGroup{
+			groups[0],
+			{Repository: miscellaneousSection, Collapsed: true, PRs: []PullRequestInfo{pr("org/small", "two")}},
+		}},
+		{name: "small repos collapsed at the end", groups: groups, minPRs: 2, expected: []repoGroup{
+			groups[0],
+			{Repository: miscellaneousSection, Collapsed: true, PRs: []PullRequestInfo{pr("org/small", "two"), pr("org/tiny", "four")}},
+		}},
+		{name: "every repo collapsed", groups: groups, minPRs: 3, expected: []repoGroup{
+			{Repository: miscellaneousSection, Collapsed: true, PRs: []PullRequestInfo{
+				pr("org/big", "one"), pr("org/big", "three"), pr("org/small", "two"), pr("org/tiny", "four"),
+			}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, collapseSmallGroups(tt.groups, tt.minPRs))
+		})
+	}
+}
+
+func TestWriteRepoGroupsMiscellaneous(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"org/big", "org/a", "org/b"}, MinPRsPerRepoSection: 2}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	prs := []PullRequestInfo{
+		{Repository: "org/big", Title: "Big one", URL: "https://github.com/org/big/pull/1"},
+		{Repository: "org/big", Title: "Big two", URL: "https://github.com/org/big/pull/2"},
+		{Repository: "org/a", Title: "Small a", URL: "https://github.com/org/a/pull/3"},
+		{Repository: "org/b", Title: "Small b", URL: "https://github.com/org/b/pull/4"},
+	}
+
+	var buf strings.Builder
+	assert.NoError(t, writeRepoGroups(&buf, prs, 2, config))
+	output := buf.String()
+
+	assert.Contains(t, output, "## org/big\n")
+	assert.Contains(t, output, "## Miscellaneous\n")
+	assert.NotContains(t, output, "## org/a\n")
+	assert.Contains(t, output, "| **Repository** | org/a |")
+	assert.Contains(t, output, "| **Repository** | org/b |")
+	assert.Equal(t, 2, strings.Count(output, "| **Repository** |"))
+}
+
+func TestRepoDisplayNames(t *testing.T) {
+	config := Config{
+		Username: "someone", OutputDir: "out", Repos: []string{"github/token-scanning-service", "github/other"},
+		RepoDisplayNames: map[string]string{"github/token-scanning-service": "Token Scanning Service"},
+	}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	prs := []PullRequestInfo{
+		{Repository: "github/token-scanning-service", Title: "Scan faster", URL: "https://github.example.com/github/token-scanning-service/pull/7"},
+		{Repository: "github/other", Title: "Other change", URL: "https://github.example.com/github/other/pull/8"},
+	}
+
+	var buf strings.Builder
+	assert.NoError(t, writeRepoGroups(&buf, prs, 2, config))
+	output := buf.String()
+	assert.Contains(t, output, "## [Token Scanning Service](https://github.example.com/github/token-scanning-service)\n")
+	assert.Contains(t, output, "## github/other\n", "unmapped repos keep owner/name")
+
+	assert.Equal(t, "Token Scanning Service (github/token-scanning-service)", repoLabel("github/token-scanning-service", config))
+	assert.Equal(t, "github/other", repoLabel("github/other", config))
+
+	t.Run("invalid entries", func(t *testing.T) {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, RepoDisplayNames: map[string]string{"not-a-repo": "Name"}}
+		assert.ErrorContains(t, config.Parse(), "invalid repo_display_names entry")
+
+		config = Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, RepoDisplayNames: map[string]string{"owner/repo": " "}}
+		assert.ErrorContains(t, config.Parse(), "repo_display_names entry for 'owner/repo' is empty")
+	})
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected string
+	}{
+		{"negative", -time.Hour, "<1m"},
+		{"under a minute", 30 * time.Second, "<1m"},
+		{"minutes", 45 * time.Minute, "45m"},
+		{"whole hours", 3 * time.Hour, "3h"},
+		{"hours and minutes", 3*time.Hour + 15*time.Minute + 40*time.Second, "3h 15m"},
+		{"whole days", 48*time.Hour + 20*time.Minute, "2d"},
+		{"days and hours", 52*time.Hour + 30*time.Minute, "2d 4h"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatDuration(tt.duration))
+		})
+	}
+}
+
+func TestAverageLeadTime(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	merged1 := created.Add(2 * time.Hour)
+	merged2 := created.Add(4 * time.Hour)
+	prs := []PullRequestInfo{
+		{CreatedAt: created, MergedAt: &merged1},
+		{CreatedAt: created, MergedAt: &merged2},
+		{CreatedAt: created},
+	}
+
+	average, known := averageLeadTime(prs)
+	assert.Equal(t, 3*time.Hour, average)
+	assert.Equal(t, 2, known)
+
+	_, ok := prLeadTime(prs[2])
+	assert.False(t, ok, "unknown merge time has no lead time")
+
+	average, known = averageLeadTime(prs[2:])
+	assert.Zero(t, average)
+	assert.Zero(t, known)
+}
+
+func TestShowEmptyRepos(t *testing.T) {
+	prs := []PullRequestInfo{
+		{Repository: "owner/busy", Title: "Change", URL: "https://github.com/owner/busy/pull/1"},
+	}
+	newConfig := func(showEmpty bool) Config {
+		config := Config{
+			Username: "someone", OutputDir: "out", Repos: []string{"owner/quiet", "owner/busy", "owner/idle"},
+			RepoDisplayNames: map[string]string{"owner/idle": "Idle Service"}, ShowEmptyRepos: showEmpty,
+		}
+		if err := config.Parse(); err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		return config
+	}
+
+	assert.Equal(t, []string{"owner/quiet", "owner/idle"}, emptyRepos(prs, newConfig(true)))
+
+	outputFile := filepath.Join(t.TempDir(), "prs.md")
+	assert.NoError(t, outputPRs(prs, nil, nil, outputFile, newConfig(true)))
+	content, err := os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "## owner/quiet\n\n*No contributions to owner/quiet in this period.*\n")
+	assert.Contains(t, string(content), "## [Idle Service](https://github.com/owner/idle)\n\n*No contributions to Idle Service (owner/idle) in this period.*\n")
+
+	assert.NoError(t, outputPRs(prs, nil, nil, outputFile, newConfig(false)))
+	content, err = os.ReadFile(outputFile)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "No contributions", "empty repos are omitted by default")
+}
+
+func TestHeadingOffsetAndSectionSeparator(t *testing.T) {
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", Title: "First", URL: "https://github.com/owner/repo/pull/1", Description: "One"},
+		{Repository: "owner/repo", Title: "Second", URL: "https://github.com/owner/repo/pull/2", Description: "Two"},
+	}
+	separator := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		offset    int
+		separator *string
+		want      []string
+		notWant   []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"# Merged Pull Requests\n", "## owner/repo\n", "### [First]", "#### Description\n", "---\n"},
+		},
+		{
+			name:    "offset shifts every heading",
+			offset:  2,
+			want:    []string{"\n### Merged Pull Requests\n", "\n#### owner/repo\n", "\n##### [First]", "\n###### Description\n"},
+			notWant: []string{"\n# Merged", "\n## owner/repo"},
+		},
+		{
+			name:    "deep headings are capped",
+			offset:  4,
+			want:    []string{"\n##### Merged Pull Requests\n", "\n###### owner/repo\n", "\n###### [First]", "\n###### Description\n"},
+			notWant: []string{"#######"},
+		},
+		{
+			name:      "custom separator",
+			separator: separator("* * *"),
+			want:      []string{"* * *\n\n### [Second]"},
+			notWant:   []string{"\n---\n"},
+		},
+		{
+			name:      "empty separator omits it",
+			separator: separator(""),
+			want:      []string{"One\n\n### [Second]"},
+			notWant:   []string{"\n---\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, HeadingOffset: tt.offset, SectionSeparator: tt.separator}
+			if err := config.Parse(); err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			outputFile := filepath.Join(t.TempDir(), "prs.md")
+			assert.NoError(t, outputPRs(prs, nil, nil, outputFile, config))
+			content, err := os.ReadFile(outputFile)
+			assert.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, "\n"+string(content), want)
+			}
+			for _, notWant := range tt.notWant {
+				assert.NotContains(t, "\n"+string(content), notWant)
+			}
+		})
+	}
+
+	for _, offset := range []int{-1, maxHeadingLevel} {
+		config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, HeadingOffset: offset}
+		assert.Error(t, config.Parse(), "heading_offset %d", offset)
+	}
+}
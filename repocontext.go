@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// repoContext is what a repository says about itself on GitHub, given to the summarizer
+// so that it knows what each repository does
+type repoContext struct {
+	Description string
+	Topics      []string
+}
+
+// fetchRepoContexts looks up the description and topics of each repository once, keyed
+// by "owner/name". Repositories that can't be looked up are left out with a warning.
+func fetchRepoContexts(ctx context.Context, client *github.Client, repos []NWO) map[string]repoContext {
+	contexts := make(map[string]repoContext, len(repos))
+	for _, repo := range repos {
+		name := fmt.Sprintf("%s/%s", repo.Owner, repo.Name)
+		if _, ok := contexts[name]; ok {
+			continue
+		}
+		r, _, err := client.Repositories.Get(ctx, repo.Owner, repo.Name)
+		if err != nil {
+			console.Warnf("Failed to get repository context for %s: %v", name, err)
+			continue
+		}
+		contexts[name] = repoContext{Description: strings.TrimSpace(r.GetDescription()), Topics: r.Topics}
+	}
+	return contexts
+}
+
+// writeRepoContext writes a heading of the given level describing each repository the PRs
+// are in, in report order, from contexts. Nothing is written if none of them has a
+// description or topics.
+func writeRepoContext(writer io.Writer, prs []PullRequestInfo, contexts map[string]repoContext, level int, config Config) {
+	// Repositories collapsed into Miscellaneous still get their own line
+	var repos []string
+	seen := make(map[string]bool)
+	for _, group := range orderRepoGroups(prs, config) {
+		for _, pr := range group.PRs {
+			if !seen[pr.Repository] {
+				seen[pr.Repository] = true
+				repos = append(repos, pr.Repository)
+			}
+		}
+	}
+
+	var lines []string
+	for _, repo := range repos {
+		repoCtx := contexts[repo]
+		if repoCtx.Description == "" && len(repoCtx.Topics) == 0 {
+			continue
+		}
+		line := fmt.Sprintf("- **%s**", repo)
+		if repoCtx.Description != "" {
+			line += ": " + repoCtx.Description
+		}
+		if len(repoCtx.Topics) > 0 {
+			line += fmt.Sprintf(" (topics: %s)", strings.Join(repoCtx.Topics, ", "))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "%s Repository Context\n\n", heading(level))
+	fmt.Fprintf(writer, "What each repository does, from its description on GitHub:\n\n")
+	fmt.Fprintf(writer, "%s\n\n", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchRepoContexts(t *testing.T) {
+	requests := 0
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/repos/owner/api":
+			w.Write([]byte(`{"full_name": "owner/api", "description": " Billing API service ", "topics": ["go", "payments"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	repos := []NWO{{Owner: "owner", Name: "api"}, {Owner: "owner", Name: "gone"}, {Owner: "owner", Name: "api"}}
+	contexts := fetchRepoContexts(context.Background(), client, repos)
+	assert.Equal(t, map[string]repoContext{
+		"owner/api": {Description: "Billing API service", Topics: []string{"go", "payments"}},
+	}, contexts)
+	assert.Equal(t, 2, requests, "each repository is looked up once")
+}
+
+func TestRepoContextInPRReport(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/api", "owner/web", "owner/docs"}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	contexts := map[string]repoContext{
+		"owner/api":  {Description: "Billing API service", Topics: []string{"go", "payments"}},
+		"owner/web":  {Topics: []string{"react"}},
+		"owner/docs": {Description: "No PRs here"},
+	}
+	prs := []PullRequestInfo{
+		{Repository: "owner/web", Title: "Web change", URL: "https://github.com/owner/web/pull/1"},
+		{Repository: "owner/api", Title: "API change", URL: "https://github.com/owner/api/pull/2"},
+	}
+
+	path := filepath.Join(t.TempDir(), "prs.md")
+	assert.NoError(t, outputPRs(prs, nil, contexts, path, config))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	text := string(data)
+
+	assert.Contains(t, text, "## Repository Context\n\nWhat each repository does, from its description on GitHub:\n\n"+
+		"- **owner/api**: Billing API service (topics: go, payments)\n- **owner/web** (topics: react)\n\n## owner/api\n")
+	assert.NotContains(t, text, "No PRs here")
+
+	t.Run("nothing known", func(t *testing.T) {
+		assert.NoError(t, outputPRs(prs, nil, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "Repository Context")
+	})
+}
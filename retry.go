@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// How many times to try a search page before giving up
+const maxSearchAttempts = 3
+
+// searchRetryDelay is the delay before the first retry of a search page, doubled for each
+// subsequent retry. It is a variable so that tests don't have to wait.
+var searchRetryDelay = 2 * time.Second
+
+// searchIssuesWithRetry runs a search, retrying transient failures with exponential backoff,
+// or after as long as GitHub asks when it reports a secondary rate limit
+func searchIssuesWithRetry(ctx context.Context, client *github.Client, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	backoff := searchRetryDelay
+	for attempt := 1; ; attempt++ {
+		result, resp, err := client.Search.Issues(ctx, query, opts)
+		if err == nil || attempt == maxSearchAttempts || !isTransientError(err) {
+			return result, resp, err
+		}
+
+		delay := retryDelay(err, backoff)
+		console.Warnf("Search page %d failed (attempt %d of %d), retrying in %s: %v", max(opts.Page, 1), attempt, maxSearchAttempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+// retryDelay returns how long to wait before retrying after err: the Retry-After of a
+// secondary rate limit if GitHub sent one, since the client refuses to make requests
+// before then anyway, or else backoff
+func retryDelay(err error, backoff time.Duration) time.Duration {
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) && abuseErr.RetryAfter != nil {
+		return *abuseErr.RetryAfter
+	}
+	return backoff
+}
+
+// isTransientError reports whether a GitHub API error is worth retrying: server errors,
+// secondary rate limits, and network failures, but not client errors like a bad query
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return true
+	}
+
+	var respErr *github.ErrorResponse
+	if errors.As(err, &respErr) {
+		return respErr.Response != nil && respErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
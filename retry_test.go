@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientError(t *testing.T) {
+	responseError := func(status int) error {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status}}
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "server error", err: responseError(http.StatusBadGateway), expected: true},
+		{name: "wrapped server error", err: fmt.Errorf("search: %w", responseError(http.StatusServiceUnavailable)), expected: true},
+		{name: "secondary rate limit", err: &github.AbuseRateLimitError{}, expected: true},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: true},
+		{name: "validation failed", err: responseError(http.StatusUnprocessableEntity), expected: false},
+		{name: "not found", err: responseError(http.StatusNotFound), expected: false},
+		{name: "canceled", err: context.Canceled, expected: false},
+		{name: "other error", err: errors.New("boom"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isTransientError(tt.err))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	retryAfter := 30 * time.Second
+	assert.Equal(t, retryAfter, retryDelay(&github.AbuseRateLimitError{RetryAfter: &retryAfter}, time.Second))
+	assert.Equal(t, time.Second, retryDelay(&github.AbuseRateLimitError{}, time.Second))
+	assert.Equal(t, time.Second, retryDelay(&github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway}}, time.Second))
+}
+
+func TestSearchIssuesWithRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff time.Duration
+		fail    func(w http.ResponseWriter)
+	}{
+		{
+			name:    "server error",
+			backoff: time.Millisecond,
+			fail: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(`{"message": "Bad Gateway"}`))
+			},
+		},
+		{
+			// The backoff would outlast the test's deadline, so this only passes if the
+			// Retry-After is used instead
+			name:    "secondary rate limit with Retry-After",
+			backoff: time.Hour,
+			fail: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "You have exceeded a secondary rate limit", "documentation_url": "https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(delay time.Duration) { searchRetryDelay = delay }(searchRetryDelay)
+			searchRetryDelay = tt.backoff
+
+			requests := 0
+			client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 1 {
+					tt.fail(w)
+					return
+				}
+				w.Write([]byte(`{"total_count": 1, "items": [{"number": 1}]}`))
+			}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			result, _, err := searchIssuesWithRetry(ctx, client, "is:pr", &github.SearchOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result.GetTotal())
+			assert.Equal(t, 2, requests)
+		})
+	}
+}
+
+func TestGetMergedPRsKeepsPagesBeforeFailure(t *testing.T) {
+	var client *github.Client
+	client = newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			if r.URL.Query().Get("page") == "2" {
+				// Not transient, so it isn't retried
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"message": "Validation Failed"}`))
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%ssearch/issues?page=2>; rel="next"`, client.BaseURL))
+			w.Write([]byte(`{"total_count": 3, "items": [
+				{"number": 1, "title": "First", "html_url": "https://github.com/owner/repo/pull/1"},
+				{"number": 2, "title": "Second", "html_url": "https://github.com/owner/repo/pull/2"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	config := Config{
+		Username:  "someone",
+		SinceTime: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		UntilTime: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	svc := newServices(context.Background(), nil, nil)
+	svc.client = client
+	prs, err := getMergedPRsWithProgress(context.Background(), svc, NWO{Owner: "owner", Name: "repo"}, config, nil)
+	assert.ErrorContains(t, err, "failed to search PRs (page 2)")
+	assert.Equal(t, []string{"First", "Second"}, titles(prs))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const rollupPrompt = `An employee is undergoing a performance review covering several periods. A summary of their contributions for each period is in %s, under a heading naming the period.
+Combine them into one summary of their major contributions over the whole time. Merge work that spanned several periods, emphasize the impact of their work and any significant features or improvements, and keep the links to PRs.
+Don't write any files. For each contribution, include an approximate date range during which the work was done.`
+
+// rollupInput is one existing summary to roll up
+type rollupInput struct {
+	Label string
+	Path  string
+}
+
+// runRollup implements the rollup subcommand, which combines existing summaries (for
+// example one per month) into a single summary without fetching anything from GitHub
+func runRollup(args []string) error {
+	flags := flag.NewFlagSet("rollup", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s rollup [flags] <summary.md or directory>...\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	var (
+		configFile = flags.String("config", "config.yaml", "Path to configuration file, for the summarizer settings")
+		outputFile = flags.String("output", "", "Where to write the combined summary (default: rollup-summary.md in output_dir)")
+		colorMode  = flags.String("color", colorAuto, "Whether to use color in terminal output: auto, always, or never")
+	)
+	flags.Parse(args)
+
+	useColor, err := resolveColor(*colorMode, stderrIsTerminal(), os.Getenv("NO_COLOR") != "")
+	if err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	console.color = useColor
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return withExitCode(exitConfig, errors.New("rollup needs at least one summary.md or directory"))
+	}
+	inputs, err := findRollupInputs(flags.Args())
+	if err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	config, err := loadConfig(*configFile, false)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	summarizer, err := newSummarizer(*config)
+	if err != nil {
+		return withExitCode(exitSummarizer, fmt.Errorf("failed to set up summarizer: %w", err))
+	}
+
+	output := *outputFile
+	if output == "" {
+		output = filepath.Join(config.OutputDir, "rollup-summary.md")
+	}
+	shouldWrite, err := confirmOverwrite(output)
+	if err != nil {
+		return fmt.Errorf("cannot check rollup file: %w", err)
+	}
+	if !shouldWrite {
+		console.Infof("Rollup %s already exists and user chose not to overwrite.", output)
+		return nil
+	}
+
+	// The summarizer reads a file next to the output, holding every summary under its period
+	outputDir := filepath.Dir(output)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputDir, err)
+	}
+	inputFile := filepath.Join(outputDir, "rollup-input.md")
+	if err := writeRollupInput(inputs, inputFile, summaryTitle(*config)); err != nil {
+		return err
+	}
+
+	console.Infof("Rolling up %d summaries with %s...", len(inputs), config.Summarizer)
+	summary, err := generateSummary(context.Background(), summarizer, inputFile, rollupPrompt, *config)
+	if err != nil {
+		return withExitCode(exitSummarizer, fmt.Errorf("failed to generate rollup summary: %w", err))
+	}
+	if err := writeSummaryToOutput(summary, output, *config); err != nil {
+		return fmt.Errorf("failed to write rollup summary: %w", err)
+	}
+
+	return nil
+}
+
+// findRollupInputs resolves the rollup arguments to summary files. A directory stands for
+// the summary.md in it and is labelled with the directory's name.
+func findRollupInputs(args []string) ([]rollupInput, error) {
+	var inputs []rollupInput
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read rollup input: %w", err)
+		}
+
+		input := rollupInput{Label: arg, Path: arg}
+		if info.IsDir() {
+			input.Path = filepath.Join(arg, "summary.md")
+			input.Label = filepath.Base(filepath.Clean(arg))
+			if _, err := os.Stat(input.Path); err != nil {
+				return nil, fmt.Errorf("cannot read rollup input: %w", err)
+			}
+		} else if filepath.Base(arg) == "summary.md" {
+			input.Label = filepath.Base(filepath.Dir(filepath.Clean(arg)))
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// writeRollupInput writes the summaries into one file, each under a heading with its label
+// in place of its own title heading
+func writeRollupInput(inputs []rollupInput, outputFile, title string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	fmt.Fprintf(writer, "# Summaries by Period\n\n")
+	for _, input := range inputs {
+		data, err := os.ReadFile(input.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", input.Path, err)
+		}
+
+		text := strings.TrimSpace(stripSummaryTitle(string(data), title))
+		fmt.Fprintf(writer, "## %s\n\n%s\n\n", input.Label, text)
+	}
+
+	return writer.Commit()
+}
+
+// stripSummaryTitle removes the first heading of summary whose text is title, at any
+// level and wherever it is, since a summary_prefix_file may come before it
+func stripSummaryTitle(summary, title string) string {
+	if title == "" {
+		return summary
+	}
+	lines := strings.Split(summary, "\n")
+	for i, line := range lines {
+		text := strings.TrimLeft(line, "#")
+		level := len(line) - len(text)
+		if level >= 1 && level <= maxHeadingLevel && strings.HasPrefix(text, " ") && strings.TrimSpace(text) == title {
+			// Along with the blank line after it
+			end := i + 1
+			if end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+				end++
+			}
+			return strings.Join(append(lines[:i:i], lines[end:]...), "\n")
+		}
+	}
+	return summary
+}
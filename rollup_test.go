@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRollup(t *testing.T) {
+	dir := t.TempDir()
+	for month, text := range map[string]string{
+		"2025-01": "# PR Summary\n\nShipped the importer.\n",
+		"2025-02": "# PR Summary\n\nMade the importer fast.\n",
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, month), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", month, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, month, "summary.md"), []byte(text), 0644); err != nil {
+			t.Fatalf("failed to write summary: %v", err)
+		}
+	}
+	extra := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(extra, []byte("Also mentored two interns.\n"), 0644); err != nil {
+		t.Fatalf("failed to write notes: %v", err)
+	}
+
+	configFile := filepath.Join(dir, "config.yaml")
+	configText := "username: someone\noutput_dir: " + filepath.Join(dir, "quarter") + "\nrepos: [owner/repo]\nsummarizer: echo\n"
+	if err := os.WriteFile(configFile, []byte(configText), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	err := runRollup([]string{"-config", configFile, "-color", "never",
+		filepath.Join(dir, "2025-01"), filepath.Join(dir, "2025-02", "summary.md"), extra})
+	assert.NoError(t, err)
+
+	input, err := os.ReadFile(filepath.Join(dir, "quarter", "rollup-input.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# Summaries by Period\n\n"+
+		"## 2025-01\n\nShipped the importer.\n\n"+
+		"## 2025-02\n\nMade the importer fast.\n\n"+
+		"## "+extra+"\n\nAlso mentored two interns.\n\n", string(input))
+
+	summary, err := os.ReadFile(filepath.Join(dir, "quarter", "rollup-summary.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(summary), "Echo summary of rollup-input.md")
+}
+
+func TestStripSummaryTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary string
+		title   string
+		want    string
+	}{
+		{name: "default title", summary: "# PR Summary\n\nShipped it.\n", title: "PR Summary", want: "Shipped it.\n"},
+		{name: "custom title", summary: "# Q1 Work\n\nShipped it.\n", title: "Q1 Work", want: "Shipped it.\n"},
+		{name: "deeper heading", summary: "## Q1 Work\n\nShipped it.\n", title: "Q1 Work", want: "Shipped it.\n"},
+		{name: "after a prefix", summary: "Written for my review.\n\n# PR Summary\n\nShipped it.\n", title: "PR Summary", want: "Written for my review.\n\nShipped it.\n"},
+		{name: "only the first", summary: "# PR Summary\n\nShipped it.\n\n# PR Summary\n", title: "PR Summary", want: "Shipped it.\n\n# PR Summary\n"},
+		{name: "other headings kept", summary: "# PR Summary Details\n\nShipped it.\n", title: "PR Summary", want: "# PR Summary Details\n\nShipped it.\n"},
+		{name: "no title", summary: "# Notes\n\nShipped it.\n", title: "", want: "# Notes\n\nShipped it.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripSummaryTitle(tt.summary, tt.title))
+		})
+	}
+}
+
+func TestFindRollupInputsMissing(t *testing.T) {
+	_, err := findRollupInputs([]string{t.TempDir()})
+	assert.ErrorContains(t, err, "summary.md")
+
+	_, err = findRollupInputs([]string{filepath.Join(t.TempDir(), "nope.md")})
+	assert.ErrorContains(t, err, "cannot read rollup input")
+}
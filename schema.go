@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// requiredConfigFields lists the YAML keys that must be present in a config file.
+// username is optional, since the token's user is looked up when it is missing. repos
+// is not listed because -list-repos-contributed runs without it; Config.Parse checks it.
+var requiredConfigFields = []string{"output_dir"}
+
+// jsonSchema is the subset of JSON Schema needed to describe the config file
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+}
+
+// configSchema derives a JSON Schema for the config file from the Config struct's YAML tags
+func configSchema() *jsonSchema {
+	schema := schemaForType(reflect.TypeOf(Config{}))
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.Title = "Employment Justifier configuration"
+	schema.Required = requiredConfigFields
+	return schema
+}
+
+// schemaForType builds the schema for a single Go type
+func schemaForType(t reflect.Type) *jsonSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		schema := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlFieldName(field)
+			if name == "" {
+				continue
+			}
+			schema.Properties[name] = schemaForType(field.Type)
+		}
+		return schema
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// yamlFieldName returns the YAML key for a struct field, or "" if the field is not serialized
+func yamlFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return ""
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// marshalConfigSchema returns the config JSON Schema as indented JSON
+func marshalConfigSchema() (string, error) {
+	data, err := json.MarshalIndent(configSchema(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode config schema: %w", err)
+	}
+	return string(data), nil
+}
+
+// validateConfigDocument checks a parsed YAML document against the config schema and
+// returns one message per problem, each pointing at the offending field and line
+func validateConfigDocument(doc *yaml.Node, required []string) []string {
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		doc = doc.Content[0]
+	}
+
+	schema := configSchema()
+	problems := validateNode(doc, schema, "")
+
+	if doc.Kind == yaml.MappingNode {
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			present[doc.Content[i].Value] = true
+		}
+		for _, field := range required {
+			if !present[field] {
+				problems = append(problems, fmt.Sprintf("missing required field '%s'", field))
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateNode checks a YAML node against a schema, using path to name the field in messages
+func validateNode(node *yaml.Node, schema *jsonSchema, path string) []string {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	describe := func(expected string) string {
+		field := path
+		if field == "" {
+			field = "(root)"
+		}
+		return fmt.Sprintf("line %d: field '%s': expected %s, got %s", node.Line, field, expected, describeNode(node))
+	}
+
+	var problems []string
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			return []string{describe("a mapping")}
+		}
+		var keys []string
+		values := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			keys = append(keys, key.Value)
+			values[key.Value] = node.Content[i+1]
+
+			fieldPath := key.Value
+			if path != "" {
+				fieldPath = path + "." + key.Value
+			}
+
+			var fieldSchema *jsonSchema
+			if schema.Properties != nil {
+				fieldSchema = schema.Properties[key.Value]
+			} else if additional, ok := schema.AdditionalProperties.(*jsonSchema); ok {
+				fieldSchema = additional
+			}
+			if fieldSchema == nil {
+				problems = append(problems, fmt.Sprintf("line %d: unknown field '%s'%s", key.Line, fieldPath, suggestField(key.Value, schema)))
+				continue
+			}
+			problems = append(problems, validateNode(node.Content[i+1], fieldSchema, fieldPath)...)
+		}
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			return []string{describe("a list")}
+		}
+		for i, item := range node.Content {
+			problems = append(problems, validateNode(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			return []string{describe("a string")}
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			return []string{describe("an integer")}
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			return []string{describe("a number")}
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			return []string{describe("true or false")}
+		}
+	}
+	return problems
+}
+
+// describeNode returns a short human-readable description of a YAML node's type
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!int":
+		return fmt.Sprintf("integer %s", node.Value)
+	case "!!float":
+		return fmt.Sprintf("number %s", node.Value)
+	case "!!bool":
+		return fmt.Sprintf("boolean %s", node.Value)
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
+
+// suggestField returns a hint naming a known field that differs from name only in
+// punctuation (e.g. "output-dir" vs "output_dir"), or "" if there is none
+func suggestField(name string, schema *jsonSchema) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(s))
+	}
+
+	var candidates []string
+	for known := range schema.Properties {
+		if normalize(known) == normalize(name) {
+			candidates = append(candidates, known)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return fmt.Sprintf(" (did you mean '%s'?)", candidates[0])
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigSchema(t *testing.T) {
+	schema := configSchema()
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, false, schema.AdditionalProperties)
+	assert.ElementsMatch(t, requiredConfigFields, schema.Required)
+
+	assert.Equal(t, "string", schema.Properties["username"].Type)
+	assert.Equal(t, "integer", schema.Properties["days"].Type)
+	assert.Equal(t, "boolean", schema.Properties["only_business_hours"].Type)
+	assert.Equal(t, "array", schema.Properties["repos"].Type)
+	assert.Equal(t, "string", schema.Properties["repos"].Items.Type)
+	assert.Contains(t, schema.Properties, "extra-prompt")
+
+	// Parsed fields are not part of the file format
+	assert.NotContains(t, schema.Properties, "SinceTime")
+	assert.NotContains(t, schema.Properties, "sincetime")
+}
+
+func TestValidateConfigDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected []string
+	}{
+		{
+			name: "valid config",
+			yaml: `username: someone
+output_dir: ./out
+days: 30
+since: 2025-05-01
+repos:
+  - owner/repo
+`,
+			expected: nil,
+		},
+		{
+			name: "repos left to Config.Parse",
+			yaml: `username: someone
+output_dir: ./out
+`,
+			expected: nil,
+		},
+		{
+			name: "unknown field with suggestion",
+			yaml: `username: someone
+output-dir: ./out
+repos: [owner/repo]
+`,
+			expected: []string{
+				"line 2: unknown field 'output-dir' (did you mean 'output_dir'?)",
+				"missing required field 'output_dir'",
+			},
+		},
+		{
+			name: "wrong scalar types",
+			yaml: `username: someone
+output_dir: ./out
+days: thirty
+only_business_hours: "yes"
+repos: [owner/repo]
+`,
+			expected: []string{
+				`line 3: field 'days': expected an integer, got "thirty"`,
+				`line 4: field 'only_business_hours': expected true or false, got "yes"`,
+			},
+		},
+		{
+			name: "repos is not a list",
+			yaml: `username: someone
+output_dir: ./out
+repos: owner/repo
+`,
+			expected: []string{
+				`line 3: field 'repos': expected a list, got "owner/repo"`,
+			},
+		},
+		{
+			name: "list item of wrong type",
+			yaml: `username: someone
+output_dir: ./out
+repos:
+  - owner/repo
+  - name: nested
+`,
+			expected: []string{
+				"line 5: field 'repos[1]': expected a string, got a mapping",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.yaml), &doc); err != nil {
+				t.Fatalf("failed to parse test YAML: %v", err)
+			}
+			assert.Equal(t, tt.expected, validateConfigDocument(&doc, requiredConfigFields))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Keys of score_weights
+const (
+	scoreWeightPR            = "pr"
+	scoreWeightComment       = "comment"
+	scoreWeightReviewComment = "review_comment"
+)
+
+// defaultScoreWeights counts each merged PR once, plus a little for the discussion it
+// drew, with line-level review comments counting double
+var defaultScoreWeights = map[string]float64{
+	scoreWeightPR:            1,
+	scoreWeightComment:       0.1,
+	scoreWeightReviewComment: 0.2,
+}
+
+// scoreWeights are the coefficients of the contribution score
+type scoreWeights struct {
+	PR            float64
+	Comment       float64
+	ReviewComment float64
+}
+
+// parseScoreWeights fills in the defaults for any weights not configured
+func parseScoreWeights(configured map[string]float64) (scoreWeights, error) {
+	merged := make(map[string]float64, len(defaultScoreWeights))
+	for key, weight := range defaultScoreWeights {
+		merged[key] = weight
+	}
+	for key, weight := range configured {
+		if _, ok := defaultScoreWeights[key]; !ok {
+			return scoreWeights{}, fmt.Errorf("unknown score weight '%s': expected one of %s", key, strings.Join(scoreWeightNames(), ", "))
+		}
+		if weight < 0 {
+			return scoreWeights{}, fmt.Errorf("score weight '%s' cannot be negative", key)
+		}
+		merged[key] = weight
+	}
+
+	return scoreWeights{
+		PR:            merged[scoreWeightPR],
+		Comment:       merged[scoreWeightComment],
+		ReviewComment: merged[scoreWeightReviewComment],
+	}, nil
+}
+
+// scoreWeightNames returns the keys of score_weights in sorted order
+func scoreWeightNames() []string {
+	var names []string
+	for name := range defaultScoreWeights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reviewDepth is how much discussion a PR drew: its conversation comments plus its
+// line-level review comments
+func reviewDepth(pr PullRequestInfo) int {
+	return pr.Comments + pr.ReviewComments
+}
+
+// contributionScore is the weighted sum over the PRs of one point per PR and a fraction
+// of a point per comment. It is a heuristic, not a measure of the work's value.
+func contributionScore(prs []PullRequestInfo, weights scoreWeights) float64 {
+	var score float64
+	for _, pr := range prs {
+		score += weights.PR + weights.Comment*float64(pr.Comments) + weights.ReviewComment*float64(pr.ReviewComments)
+	}
+	return score
+}
+
+// scoreFormula describes how the contribution score is computed, for report headers
+func scoreFormula(weights scoreWeights) string {
+	return fmt.Sprintf("%g per PR + %g per comment + %g per review comment", weights.PR, weights.Comment, weights.ReviewComment)
+}
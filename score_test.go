@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScoreWeights(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured map[string]float64
+		expected   scoreWeights
+		err        string
+	}{
+		{name: "defaults", expected: scoreWeights{PR: 1, Comment: 0.1, ReviewComment: 0.2}},
+		{name: "partial override", configured: map[string]float64{"review_comment": 0.5}, expected: scoreWeights{PR: 1, Comment: 0.1, ReviewComment: 0.5}},
+		{name: "zero disables a term", configured: map[string]float64{"comment": 0}, expected: scoreWeights{PR: 1, Comment: 0, ReviewComment: 0.2}},
+		{name: "unknown key", configured: map[string]float64{"approvals": 1}, err: "unknown score weight 'approvals': expected one of comment, pr, review_comment"},
+		{name: "negative", configured: map[string]float64{"pr": -1}, err: "score weight 'pr' cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := parseScoreWeights(tt.configured)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, weights)
+		})
+	}
+}
+
+func TestContributionScore(t *testing.T) {
+	prs := []PullRequestInfo{
+		{Title: "quiet"},
+		{Title: "discussed", Comments: 5, ReviewComments: 10},
+	}
+	weights := scoreWeights{PR: 1, Comment: 0.1, ReviewComment: 0.2}
+
+	assert.Equal(t, 15, reviewDepth(prs[1]))
+	assert.InDelta(t, 4.5, contributionScore(prs, weights), 1e-9)
+	assert.Zero(t, contributionScore(nil, weights))
+}
+
+func TestContributionScoreInReports(t *testing.T) {
+	config := Config{Username: "alice", OutputDir: "out", Repos: []string{"owner/repo"}, ContributionScore: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	prs := []PullRequestInfo{{Repository: "owner/repo", Title: "Discussed", URL: "https://github.com/owner/repo/pull/1", Comments: 2, ReviewComments: 4}}
+
+	t.Run("prs.md", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "prs.md")
+		assert.NoError(t, outputPRs(prs, nil, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "Contribution score: 2.0 (a rough heuristic, not a measure of impact: 1 per PR + 0.1 per comment + 0.2 per review comment).")
+		assert.Contains(t, string(data), "| **Review depth** | 6 (2 comments, 4 review comments) |")
+	})
+
+	t.Run("team report", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "team-report.md")
+		assert.NoError(t, outputTeamReport([]userReport{{Username: "alice", PRs: prs}, {Username: "bob"}}, nil, path, config))
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "| Author | Merged PRs | Repositories | Contribution Score |\n|--------|------------|--------------|--------------------|\n")
+		assert.Contains(t, string(data), "Contribution scores are a rough heuristic")
+		assert.Contains(t, string(data), "| alice | 1 | 1 | 2.0 |")
+		assert.Contains(t, string(data), "| bob | 0 | 0 | 0.0 |")
+	})
+
+	t.Run("weights need the score", func(t *testing.T) {
+		config := Config{Username: "alice", OutputDir: "out", Repos: []string{"owner/repo"}, ScoreWeights: map[string]float64{"pr": 2}}
+		assert.EqualError(t, config.Parse(), "score_weights requires contribution_score")
+	})
+}
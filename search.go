@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// searchQualifier is one qualifier of a search query, e.g. "is:pr". Qualifiers that only
+// narrow the results down by something the results themselves show have a client-side
+// equivalent in Keep, so they can be dropped from a query that GitHub rejects.
+type searchQualifier struct {
+	Text string
+	Keep func(issue *github.Issue) bool // nil if the qualifier can't be dropped
+}
+
+// searchQuery is a GitHub search query that can fall back to client-side filtering
+type searchQuery struct {
+	qualifiers []searchQualifier
+	clientSide []searchQualifier // dropped from the query, applied to the results instead
+}
+
+// newSearchQuery creates a query from its qualifiers
+func newSearchQuery(qualifiers ...searchQualifier) *searchQuery {
+	return &searchQuery{qualifiers: qualifiers}
+}
+
+// qualifier is a search qualifier that must stay in the query
+func qualifier(text string) searchQualifier {
+	return searchQualifier{Text: text}
+}
+
+// createdQualifier is "created:<since>..<until>", inclusive of both dates
+func createdQualifier(since, until time.Time) searchQualifier {
+	from, to := since.Format(dateFormat), until.Format(dateFormat)
+	return searchQualifier{
+		Text: "created:" + from + ".." + to,
+		Keep: func(issue *github.Issue) bool {
+			created := issue.GetCreatedAt().UTC().Format(dateFormat)
+			return created >= from && created <= to
+		},
+	}
+}
+
+// mergedQualifier is "merged:<since>..<until>", inclusive of both UTC dates. Search
+// results don't show when a PR was merged, but a merged PR was closed by merging it, so
+// the client-side equivalent goes by the close time.
+func mergedQualifier(since, until time.Time) searchQualifier {
+	from, to := since.UTC().Format(dateFormat), until.UTC().Format(dateFormat)
+	return searchQualifier{
+		Text: "merged:" + from + ".." + to,
+		Keep: func(issue *github.Issue) bool {
+			closed := issue.GetClosedAt().UTC().Format(dateFormat)
+			return closed >= from && closed <= to
+		},
+	}
+}
+
+// windowQualifier limits a search for merged PRs to the report window. A window between
+// two releases is about when PRs were merged, so it searches by merge date and leaves
+// trimming to the exact release times to releaseWindowFilter; otherwise it searches by
+// creation date.
+func windowQualifier(config Config) searchQualifier {
+	return windowQualifierBetween(config, config.SinceTime, config.UntilTime)
+}
+
+// windowQualifierBetween is windowQualifier for the part of the report window from since
+// to until
+func windowQualifierBetween(config Config, since, until time.Time) searchQualifier {
+	if config.usesReleaseTags() {
+		return mergedQualifier(since, until)
+	}
+	return createdQualifier(since, until)
+}
+
+// createdBeforeQualifier is "created:<=<until>"
+func createdBeforeQualifier(until time.Time) searchQualifier {
+	to := until.Format(dateFormat)
+	return searchQualifier{
+		Text: "created:<=" + to,
+		Keep: func(issue *github.Issue) bool {
+			return issue.GetCreatedAt().UTC().Format(dateFormat) <= to
+		},
+	}
+}
+
+// updatedSinceQualifier is "updated:>=<since>"
+func updatedSinceQualifier(since time.Time) searchQualifier {
+	from := since.Format(dateFormat)
+	return searchQualifier{
+		Text: "updated:>=" + from,
+		Keep: func(issue *github.Issue) bool {
+			return issue.GetUpdatedAt().UTC().Format(dateFormat) >= from
+		},
+	}
+}
+
+// String returns the query as sent to GitHub
+func (q *searchQuery) String() string {
+	texts := make([]string, len(q.qualifiers))
+	for i, qualifier := range q.qualifiers {
+		texts[i] = qualifier.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// degrade moves the longest qualifier that has a client-side equivalent out of the
+// query. It returns the qualifier moved, or false if there is none left to move.
+func (q *searchQuery) degrade() (searchQualifier, bool) {
+	var droppable []int
+	for i, qualifier := range q.qualifiers {
+		if qualifier.Keep != nil {
+			droppable = append(droppable, i)
+		}
+	}
+	if len(droppable) == 0 {
+		return searchQualifier{}, false
+	}
+	sort.SliceStable(droppable, func(i, j int) bool {
+		return len(q.qualifiers[droppable[i]].Text) > len(q.qualifiers[droppable[j]].Text)
+	})
+
+	i := droppable[0]
+	dropped := q.qualifiers[i]
+	q.qualifiers = append(q.qualifiers[:i:i], q.qualifiers[i+1:]...)
+	q.clientSide = append(q.clientSide, dropped)
+	return dropped, true
+}
+
+// filter returns the search results that pass the qualifiers dropped from the query
+func (q *searchQuery) filter(issues []*github.Issue) []*github.Issue {
+	if len(q.clientSide) == 0 {
+		return issues
+	}
+	var kept []*github.Issue
+	for _, issue := range issues {
+		keep := true
+		for _, qualifier := range q.clientSide {
+			keep = keep && qualifier.Keep(issue)
+		}
+		if keep {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// searchIssuesDegrading runs a search like searchIssuesWithRetry. When GitHub rejects the
+// query as too long, it drops qualifiers from it one at a time and tries again; the
+// caller must then pass the results through query.filter. The query stays degraded for
+// later pages.
+func searchIssuesDegrading(ctx context.Context, client *github.Client, query *searchQuery, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	for {
+		result, resp, err := searchIssuesWithRetry(ctx, client, query.String(), opts)
+		if err == nil || !isQueryTooLongError(err) {
+			return result, resp, err
+		}
+		dropped, ok := query.degrade()
+		if !ok {
+			return result, resp, err
+		}
+		console.Warnf("GitHub rejected the search query as too long; searching without '%s' and filtering the results client-side instead: %s", dropped.Text, query)
+	}
+}
+
+// isQueryTooLongError reports whether GitHub rejected a search because the query is too
+// long or has too many qualifiers
+func isQueryTooLongError(err error) bool {
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+
+	messages := []string{respErr.Message}
+	for _, e := range respErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	for _, message := range messages {
+		message = strings.ToLower(message)
+		if strings.Contains(message, "longer than") || strings.Contains(message, "too long") || strings.Contains(message, "more than") {
+			return true
+		}
+	}
+	return false
+}
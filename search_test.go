@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	config := Config{
+		Username:  "someone",
+		SinceTime: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		UntilTime: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "repo:owner/repo is:pr is:merged author:someone created:2025-05-01..2025-05-31",
+		buildSearchQuery(NWO{Owner: "owner", Name: "repo"}, config).String())
+}
+
+func TestBuildSearchQueryReleaseWindow(t *testing.T) {
+	// A PR created before since_tag but merged between the releases must be found, so the
+	// search goes by merge date; releaseWindowFilter trims to the release times
+	config := Config{
+		Username:  "someone",
+		SinceTag:  "v2.3",
+		UntilTag:  "v2.4",
+		SinceTime: time.Date(2025, 5, 1, 15, 30, 0, 0, time.UTC),
+		UntilTime: time.Date(2025, 5, 31, 9, 0, 0, 0, time.UTC),
+	}
+	repo := NWO{Owner: "owner", Name: "repo"}
+	assert.Equal(t, "repo:owner/repo is:pr is:merged author:someone merged:2025-05-01..2025-05-31",
+		buildSearchQuery(repo, config).String())
+	assert.Equal(t, "repo:owner/repo is:pr is:merged -author:someone merged:2025-05-01..2025-05-31",
+		buildCoAuthorSearchQuery(repo, config).String())
+
+	merged := windowQualifier(config)
+	closedAt := func(t time.Time) *github.Issue {
+		return &github.Issue{
+			CreatedAt: &github.Timestamp{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			ClosedAt:  &github.Timestamp{Time: t},
+		}
+	}
+	assert.True(t, merged.Keep(closedAt(time.Date(2025, 5, 31, 23, 0, 0, 0, time.UTC))), "created long before the window")
+	assert.False(t, merged.Keep(closedAt(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))))
+}
+
+func TestSearchQueryDegrade(t *testing.T) {
+	since := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+	query := newSearchQuery(qualifier("is:pr"), createdBeforeQualifier(until), updatedSinceQualifier(since))
+
+	issue := func(created, updated time.Time) *github.Issue {
+		return &github.Issue{CreatedAt: &github.Timestamp{Time: created}, UpdatedAt: &github.Timestamp{Time: updated}}
+	}
+	inRange := issue(time.Date(2025, 5, 31, 23, 0, 0, 0, time.UTC), time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC))
+	createdLate := issue(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC))
+	staleUpdate := issue(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC))
+	issues := []*github.Issue{inRange, createdLate, staleUpdate}
+
+	assert.Equal(t, issues, query.filter(issues), "nothing to filter before degrading")
+
+	dropped, ok := query.degrade()
+	assert.True(t, ok)
+	assert.Equal(t, "created:<=2025-05-31", dropped.Text, "longest first")
+	assert.Equal(t, "is:pr updated:>=2025-05-01", query.String())
+	assert.Equal(t, []*github.Issue{inRange, staleUpdate}, query.filter(issues))
+
+	dropped, ok = query.degrade()
+	assert.True(t, ok)
+	assert.Equal(t, "updated:>=2025-05-01", dropped.Text)
+	assert.Equal(t, "is:pr", query.String())
+	assert.Equal(t, []*github.Issue{inRange}, query.filter(issues))
+
+	_, ok = query.degrade()
+	assert.False(t, ok, "required qualifiers stay")
+}
+
+func TestSearchIssuesDegrading(t *testing.T) {
+	var queries []string
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		queries = append(queries, query)
+		if len(query) > 30 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "Validation Failed", "errors": [{"message": "The search is longer than 256 characters.", "resource": "Search", "field": "q", "code": "invalid"}]}`))
+			return
+		}
+		w.Write([]byte(`{"total_count": 2, "items": [
+			{"number": 1, "created_at": "2025-05-10T10:00:00Z"},
+			{"number": 2, "created_at": "2025-07-01T10:00:00Z"}
+		]}`))
+	}))
+
+	query := newSearchQuery(qualifier("is:pr"), qualifier("author:someone"),
+		createdQualifier(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)))
+	opts := &github.SearchOptions{}
+
+	result, _, err := searchIssuesDegrading(context.Background(), client, query, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"is:pr author:someone created:2025-05-01..2025-05-31", "is:pr author:someone"}, queries)
+	assert.Len(t, result.Issues, 2, "results are returned unfiltered")
+	kept := query.filter(result.Issues)
+	assert.Len(t, kept, 1)
+	assert.Equal(t, 1, kept[0].GetNumber())
+
+	t.Run("other errors are returned", func(t *testing.T) {
+		queries = nil
+		query := newSearchQuery(qualifier("is:pr"), qualifier("author:someone-with-a-very-long-name"))
+		_, _, err := searchIssuesDegrading(context.Background(), client, query, opts)
+		assert.Error(t, err)
+		assert.Len(t, queries, 1, "nothing left to drop")
+	})
+}
+
+func TestIsQueryTooLongError(t *testing.T) {
+	unprocessable := &http.Response{StatusCode: http.StatusUnprocessableEntity}
+	assert.True(t, isQueryTooLongError(&github.ErrorResponse{Response: unprocessable, Message: "Validation Failed",
+		Errors: []github.Error{{Message: "The search is longer than 256 characters."}}}))
+	assert.True(t, isQueryTooLongError(&github.ErrorResponse{Response: unprocessable, Message: "Validation Failed",
+		Errors: []github.Error{{Message: "The search contains more than 5 AND / OR / NOT operators."}}}))
+	assert.False(t, isQueryTooLongError(&github.ErrorResponse{Response: unprocessable, Message: "Validation Failed",
+		Errors: []github.Error{{Message: "The listed users cannot be searched"}}}))
+	assert.False(t, isQueryTooLongError(&github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusInternalServerError}, Message: "too long"}))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// writePRsJSON writes PRs as a JSON snapshot that later runs can diff against
+func writePRsJSON(prs []PullRequestInfo, outputFile string) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing PR snapshot to %s", outputFile)
+	}
+
+	if prs == nil {
+		prs = []PullRequestInfo{}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(prs); err != nil {
+		return fmt.Errorf("failed to encode PR snapshot: %w", err)
+	}
+
+	return writer.Commit()
+}
+
+// loadPRsJSON reads a JSON snapshot written by writePRsJSON
+func loadPRsJSON(path string) ([]PullRequestInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PR snapshot %s: %w", path, err)
+	}
+
+	var prs []PullRequestInfo
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR snapshot %s: %w", path, err)
+	}
+
+	return prs, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRsJSONRoundTrip(t *testing.T) {
+	mergedAt := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+	prs := []PullRequestInfo{
+		{
+			Author:      "someone",
+			Repository:  "owner/repo",
+			Title:       "Add feature",
+			Description: "Details",
+			URL:         "https://github.com/owner/repo/pull/1",
+			CreatedAt:   time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC),
+			MergedAt:    &mergedAt,
+		},
+		{
+			Repository: "owner/repo",
+			Title:      "Unknown merge time",
+			URL:        "https://github.com/owner/repo/pull/2",
+			CreatedAt:  time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "prs.json")
+	assert.NoError(t, writePRsJSON(prs, path))
+
+	loaded, err := loadPRsJSON(path)
+	assert.NoError(t, err)
+	assert.Equal(t, prs, loaded)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stackReferencePattern matches a description saying that its PR builds on another one
+var stackReferencePattern = regexp.MustCompile(`(?i)\b(?:stacked on(?: top of)?|part of)\s+#(\d+)\b`)
+
+// longLivedBranches are branch names conventionally used to integrate many changes, such as
+// develop in a develop-to-main flow, rather than to hold one change that others build on
+var longLivedBranches = []string{"main", "master", "develop", "development", "dev", "trunk", "staging", "production"}
+
+// isLongLivedBranch reports whether pr's head branch is its repository's default branch or
+// another long-lived one, which PRs based on it are not stacked on
+func isLongLivedBranch(pr PullRequestInfo) bool {
+	return pr.HeadRef == pr.DefaultBranch || slices.Contains(longLivedBranches, pr.HeadRef) ||
+		strings.HasPrefix(pr.HeadRef, "release/")
+}
+
+// findStacks finds chains of stacked PRs among prs. A PR is stacked on another PR of the
+// same repository if its description says "Stacked on #N" or "Part of #N", or if its base
+// branch is the head branch of that PR alone, that branch isn't long-lived, and that PR
+// was opened first. It returns each chain of two or more PRs as indexes into prs, bottom
+// of the stack first, with the chains ordered by their first index. PRs linked in any
+// shape other than a single chain, such as two PRs stacked on the same one, are left out.
+func findStacks(prs []PullRequestInfo) [][]int {
+	type numberKey struct {
+		Repository string
+		Number     int
+	}
+	type branchKey struct {
+		Repository string
+		Branch     string
+	}
+	byNumber := make(map[numberKey]int)
+	byHead := make(map[branchKey][]int)
+	for i, pr := range prs {
+		byNumber[numberKey{pr.Repository, pr.Number}] = i
+		if pr.HeadRef != "" {
+			key := branchKey{pr.Repository, pr.HeadRef}
+			byHead[key] = append(byHead[key], i)
+		}
+	}
+
+	// stackedOn[i] holds the PRs that PR i is stacked on
+	stackedOn := make([][]int, len(prs))
+	stack := func(i, j int) {
+		if j != i && !slices.Contains(stackedOn[i], j) {
+			stackedOn[i] = append(stackedOn[i], j)
+		}
+	}
+	for i, pr := range prs {
+		// A branch name several PRs used, such as a reused "patch-1", doesn't say which
+		// of them this one builds on
+		if heads := byHead[branchKey{pr.Repository, pr.BaseRef}]; pr.BaseRef != "" && len(heads) == 1 {
+			if j := heads[0]; !isLongLivedBranch(prs[j]) && prs[j].CreatedAt.Before(pr.CreatedAt) {
+				stack(i, j)
+			}
+		}
+		for _, match := range stackReferencePattern.FindAllStringSubmatch(pr.Description, -1) {
+			number, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+			if j, ok := byNumber[numberKey{pr.Repository, number}]; ok {
+				stack(i, j)
+			}
+		}
+	}
+
+	// Union-find over the PRs to collect the linked ones together
+	parent := make([]int, len(prs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, below := range stackedOn {
+		for _, j := range below {
+			parent[find(i)] = find(j)
+		}
+	}
+
+	components := make(map[int][]int)
+	for i := range prs {
+		root := find(i)
+		components[root] = append(components[root], i)
+	}
+
+	var stacks [][]int
+	for _, component := range components {
+		if len(component) < 2 {
+			continue
+		}
+		if chain := linearStack(component, stackedOn); chain != nil {
+			stacks = append(stacks, chain)
+		}
+	}
+	sort.Slice(stacks, func(a, b int) bool {
+		return slices.Min(stacks[a]) < slices.Min(stacks[b])
+	})
+	return stacks
+}
+
+// linearStack returns the PRs of component in stack order, bottom first, or nil if they
+// aren't a single chain: some PR is stacked on two PRs, two PRs are stacked on the same
+// one, or the links go round in a circle
+func linearStack(component []int, stackedOn [][]int) []int {
+	above := make(map[int]int)
+	bottom := -1
+	for _, i := range component {
+		switch len(stackedOn[i]) {
+		case 0:
+			if bottom >= 0 {
+				return nil
+			}
+			bottom = i
+		case 1:
+			j := stackedOn[i][0]
+			if _, taken := above[j]; taken {
+				return nil
+			}
+			above[j] = i
+		default:
+			return nil
+		}
+	}
+	if bottom < 0 {
+		return nil
+	}
+
+	chain := []int{bottom}
+	for next, ok := above[bottom]; ok; next, ok = above[next] {
+		chain = append(chain, next)
+	}
+	if len(chain) != len(component) {
+		return nil
+	}
+	return chain
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStacks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		prs      []PullRequestInfo
+		expected [][]int
+	}{
+		{
+			name: "no stacks",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, BaseRef: "main", HeadRef: "a"},
+				{Repository: "o/r", Number: 2, BaseRef: "main", HeadRef: "b"},
+			},
+		},
+		{
+			name: "base is another PR's head",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 3, BaseRef: "part-2", HeadRef: "part-3", CreatedAt: day(3)},
+				{Repository: "o/r", Number: 9, BaseRef: "main", HeadRef: "other", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 1, BaseRef: "main", HeadRef: "part-1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "part-1", HeadRef: "part-2", CreatedAt: day(2)},
+			},
+			expected: [][]int{{2, 3, 0}},
+		},
+		{
+			name: "description references",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 10, Description: "First step.", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 11, Description: "Stacked on #10.", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 12, Description: "This is part of #11", CreatedAt: day(3)},
+				{Repository: "o/r", Number: 13, Description: "Part of #99, which isn't a fetched PR", CreatedAt: day(4)},
+			},
+			expected: [][]int{{0, 1, 2}},
+		},
+		{
+			name: "only within a repository",
+			prs: []PullRequestInfo{
+				{Repository: "o/a", Number: 1, HeadRef: "feature"},
+				{Repository: "o/b", Number: 2, BaseRef: "feature", Description: "Stacked on #1"},
+			},
+		},
+		{
+			name: "separate chains",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, HeadRef: "x1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 5, HeadRef: "y1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "x1", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 6, BaseRef: "y1", CreatedAt: day(2)},
+			},
+			expected: [][]int{{0, 2}, {1, 3}},
+		},
+		{
+			name: "long-lived branches",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, BaseRef: "main", HeadRef: "develop", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "develop", HeadRef: "feature-a", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 3, BaseRef: "develop", HeadRef: "feature-b", CreatedAt: day(3)},
+				{Repository: "o/r", Number: 4, BaseRef: "trunk-2024", HeadRef: "hotfix", CreatedAt: day(3)},
+				{Repository: "o/r", Number: 5, BaseRef: "other", HeadRef: "trunk-2024", DefaultBranch: "trunk-2024", CreatedAt: day(2)},
+			},
+		},
+		{
+			name: "reused branch names",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, BaseRef: "main", HeadRef: "patch-1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "main", HeadRef: "patch-1", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 3, BaseRef: "patch-1", HeadRef: "patch-2", CreatedAt: day(3)},
+			},
+		},
+		{
+			name: "base opened after the PR",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, BaseRef: "cleanup", HeadRef: "feature", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "main", HeadRef: "cleanup", CreatedAt: day(2)},
+			},
+		},
+		{
+			name: "branching stacks are left out",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, Description: "Tracking PR", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, Description: "Part of #1", CreatedAt: day(2)},
+				{Repository: "o/r", Number: 3, Description: "Part of #1", CreatedAt: day(3)},
+				{Repository: "o/r", Number: 4, HeadRef: "x1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 5, BaseRef: "x1", CreatedAt: day(2)},
+			},
+			expected: [][]int{{3, 4}},
+		},
+		{
+			name: "circular references",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, Description: "Stacked on #2", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, Description: "Stacked on #1", CreatedAt: day(2)},
+			},
+		},
+		{
+			name: "branch and description agree",
+			prs: []PullRequestInfo{
+				{Repository: "o/r", Number: 1, HeadRef: "step-1", CreatedAt: day(1)},
+				{Repository: "o/r", Number: 2, BaseRef: "step-1", Description: "Stacked on #1", CreatedAt: day(2)},
+			},
+			expected: [][]int{{0, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findStacks(tt.prs))
+		})
+	}
+}
+
+func TestWriteStackedPRs(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prs := []PullRequestInfo{
+		{Repository: "o/r", Number: 1, Title: "Add the model", URL: "https://github.com/o/r/pull/1", HeadRef: "model", CreatedAt: created},
+		{Repository: "o/r", Number: 7, Title: "Unrelated fix", URL: "https://github.com/o/r/pull/7", CreatedAt: created.Add(time.Hour)},
+		{Repository: "o/r", Number: 2, Title: "Add the API", URL: "https://github.com/o/r/pull/2", BaseRef: "model", CreatedAt: created.Add(2 * time.Hour)},
+	}
+
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"o/r"}, GroupStackedPRs: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var buf strings.Builder
+	assert.NoError(t, writeRepoGroups(&buf, prs, 2, config))
+	output := buf.String()
+
+	assert.Contains(t, output, "### Stacked PRs: Add the model (2 PRs)\n")
+	assert.Contains(t, output, "#### [Add the model](https://github.com/o/r/pull/1)")
+	assert.Contains(t, output, "#### [Add the API](https://github.com/o/r/pull/2)")
+	assert.Contains(t, output, "### [Unrelated fix](https://github.com/o/r/pull/7)")
+	assert.Contains(t, output, "| **Stack** | 2 of 2 |")
+	assert.Less(t, strings.Index(output, "Add the API"), strings.Index(output, "Unrelated fix"), "the chain stays together")
+}
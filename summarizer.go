@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Summarizer backends
+	summarizerCopilot = "copilot"
+	summarizerChat    = "chat"
+	summarizerEcho    = "echo"
+
+	// Default endpoint for the chat backend (Ollama's OpenAI-compatible API)
+	defaultChatURL = "http://localhost:11434/v1"
+
+	// How long to wait for a chat completion before giving up
+	chatTimeout = 10 * time.Minute
+)
+
+// SummaryRequest describes a single summarization
+type SummaryRequest struct {
+	// SystemPrompt holds optional standing instructions. Backends with a system role send
+	// it there; others prepend it to the prompt.
+	SystemPrompt string
+	// Prompt holds the task instructions, which refer to InputFile as given by
+	// inputReference
+	Prompt string
+	// InputFile is the Markdown file to summarize
+	InputFile string
+}
+
+// Summarizer turns a Markdown file of PR descriptions into prose
+type Summarizer interface {
+	Summarize(ctx context.Context, req SummaryRequest) (string, error)
+}
+
+// inlinedInputReference is how prompts refer to the input for backends that are sent the
+// input's content after the prompt rather than reading the file themselves
+const inlinedInputReference = "the document below"
+
+// inputReference returns how a prompt for the given backend should refer to inputFile:
+// as "@name" for the copilot CLI, which reads the file itself, or as the content that
+// follows the prompt for backends that are sent it directly
+func inputReference(backend, inputFile string) string {
+	switch backend {
+	case summarizerCopilot, "":
+		return "@" + filepath.Base(inputFile)
+	default:
+		return inlinedInputReference
+	}
+}
+
+// newSummarizer creates the summarizer backend selected in the configuration, limited to
+// summarizer_concurrency calls at a time and, with StrictSummarizer, failing if it
+// changes any files
+func newSummarizer(config Config) (Summarizer, error) {
+	backend, err := newSummarizerBackend(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.StrictSummarizer {
+		backend = newGuardedSummarizer(backend, config.OutputDir)
+	}
+	return newLimitedSummarizer(backend, config.SummarizerConcurrency), nil
+}
+
+// summarizerWorkers returns how many users to summarize at once: summarizer_concurrency,
+// or one with StrictSummarizer, since otherwise one user's summary being written would
+// look like another user's summarizer changing files
+func summarizerWorkers(config Config) int {
+	if config.StrictSummarizer {
+		return 1
+	}
+	return config.SummarizerConcurrency
+}
+
+// newSummarizerBackend creates the backend named by config.Summarizer
+func newSummarizerBackend(config Config) (Summarizer, error) {
+	switch config.Summarizer {
+	case summarizerCopilot, "":
+		return copilotSummarizer{}, nil
+	case summarizerEcho:
+		return echoSummarizer{}, nil
+	case summarizerChat:
+		var apiKey string
+		if config.ChatAPIKeyEnv != "" {
+			apiKey = os.Getenv(config.ChatAPIKeyEnv)
+			if apiKey == "" {
+				return nil, fmt.Errorf("environment variable %s (chat_api_key_env) is not set", config.ChatAPIKeyEnv)
+			}
+		}
+		return chatSummarizer{
+			URL:    config.ChatURL,
+			Model:  config.ChatModel,
+			APIKey: apiKey,
+			Client: &http.Client{Timeout: chatTimeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown summarizer '%s'", config.Summarizer)
+	}
+}
+
+// limitedSummarizer bounds how many calls to the wrapped summarizer run at once, so that
+// parallel work doesn't start a crowd of copilot processes or trip an API's rate limit
+type limitedSummarizer struct {
+	backend Summarizer
+	slots   chan struct{}
+}
+
+// newLimitedSummarizer wraps backend so that at most limit calls run at once. A limit
+// below one is treated as one.
+func newLimitedSummarizer(backend Summarizer, limit int) *limitedSummarizer {
+	return &limitedSummarizer{backend: backend, slots: make(chan struct{}, max(limit, 1))}
+}
+
+// Summarize implements Summarizer, waiting for a free slot first
+func (s *limitedSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-s.slots }()
+
+	return s.backend.Summarize(ctx, req)
+}
+
+// echoSummarizer returns a deterministic description of its input instead of a summary,
+// for testing the pipeline without an external summarizer
+type echoSummarizer struct{}
+
+// foundPRsRegexp matches the PR count every PR report states under its title, whatever
+// its PR template or metadata fields
+var foundPRsRegexp = regexp.MustCompile(`(?m)^Found (\d+) merged pull requests`)
+
+// Summarize implements Summarizer
+func (echoSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	content, err := os.ReadFile(req.InputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", req.InputFile, err)
+	}
+
+	prCount := 0
+	if match := foundPRsRegexp.FindSubmatch(content); match != nil {
+		prCount, _ = strconv.Atoi(string(match[1]))
+	}
+	return fmt.Sprintf("Echo summary of %s: %d PRs, prompt of %d characters, system prompt of %d characters.",
+		filepath.Base(req.InputFile), prCount, len(req.Prompt), len(req.SystemPrompt)), nil
+}
+
+// copilotSummarizer runs the copilot CLI, which reads the input file itself
+type copilotSummarizer struct{}
+
+// Summarize uses the copilot CLI to generate a summary of the input file
+func (copilotSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	// Get the directory containing the input file
+	inputDir, err := filepath.Abs(filepath.Dir(req.InputFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for directory: %w", err)
+	}
+
+	// The CLI has no system role, so standing instructions go first in the prompt
+	prompt := req.Prompt
+	if req.SystemPrompt != "" {
+		prompt = fmt.Sprintf("%s\n\n%s", req.SystemPrompt, prompt)
+	}
+
+	console.Infof("Copilot prompt: %s", prompt)
+
+	// Use copilot CLI with the directory added and reference the filename in the prompt
+	cmd := exec.CommandContext(ctx, "copilot", "--disable-builtin-mcps", "--deny-tool", "--no-color", "--no-custom-instructions", "--add-dir", inputDir, "-p", prompt)
+	cmd.Dir = inputDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		// If there's an error, try to get stderr for more details
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("failed to run copilot CLI: %w\nStderr: %s", err, string(exitError.Stderr))
+		}
+		return "", fmt.Errorf("failed to run copilot CLI: %w (make sure copilot CLI is installed and available)", err)
+	}
+
+	summary := strings.TrimSpace(string(output))
+	if summary == "" {
+		return "", fmt.Errorf("copilot CLI returned empty summary")
+	}
+
+	return summary, nil
+}
+
+// chatSummarizer calls an OpenAI-compatible chat completions API (OpenAI, Ollama, and
+// many others), sending the system prompt as the system message and the prompt plus
+// the input file's content as the user message
+type chatSummarizer struct {
+	URL    string
+	Model  string
+	APIKey string
+	Client *http.Client
+}
+
+// chatMessage is one message in a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the body of a chat completions request
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+// chatResponse is the subset of a chat completions response we need
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize sends the request to the chat completions endpoint
+func (s chatSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	content, err := os.ReadFile(req.InputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", req.InputFile, err)
+	}
+
+	var messages []chatMessage
+	if req.SystemPrompt != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: req.SystemPrompt})
+	}
+	messages = append(messages, chatMessage{
+		Role:    "user",
+		Content: fmt.Sprintf("%s\n\n%s", req.Prompt, content),
+	})
+
+	body, err := json.Marshal(chatRequest{Model: s.Model, Messages: messages})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode chat request: %w", err)
+	}
+
+	endpoint := strings.TrimRight(s.URL, "/") + "/chat/completions"
+	console.Infof("Sending %d bytes to %s (model %s)", len(body), endpoint, s.Model)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+s.APIKey)
+	}
+
+	resp, err := s.Client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to call chat API at %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chat API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("chat API returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var parsed chatResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", fmt.Errorf("failed to parse chat API response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("chat API returned no choices")
+	}
+
+	summary := strings.TrimSpace(parsed.Choices[0].Message.Content)
+	if summary == "" {
+		return "", fmt.Errorf("chat API returned empty summary")
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatSummarizer(t *testing.T) {
+	var received chatRequest
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		authHeader = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  A fine summary.  "}}]}`))
+	}))
+	defer server.Close()
+
+	inputFile := filepath.Join(t.TempDir(), "prs.md")
+	if err := os.WriteFile(inputFile, []byte("# Merged Pull Requests"), 0644); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+
+	summarizer := chatSummarizer{URL: server.URL + "/v1/", Model: "llama3", APIKey: "secret", Client: server.Client()}
+
+	t.Run("system and user messages", func(t *testing.T) {
+		summary, err := summarizer.Summarize(context.Background(), SummaryRequest{
+			SystemPrompt: "You are a helpful reviewer.",
+			Prompt:       "Summarize the PRs.",
+			InputFile:    inputFile,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "A fine summary.", summary)
+		assert.Equal(t, "Bearer secret", authHeader)
+		assert.Equal(t, "llama3", received.Model)
+		assert.Equal(t, []chatMessage{
+			{Role: "system", Content: "You are a helpful reviewer."},
+			{Role: "user", Content: "Summarize the PRs.\n\n# Merged Pull Requests"},
+		}, received.Messages)
+	})
+
+	t.Run("no system prompt", func(t *testing.T) {
+		_, err := summarizer.Summarize(context.Background(), SummaryRequest{Prompt: "Summarize the PRs.", InputFile: inputFile})
+		assert.NoError(t, err)
+		if assert.Len(t, received.Messages, 1) {
+			assert.Equal(t, "user", received.Messages[0].Role)
+		}
+	})
+}
+
+func TestChatSummarizerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	inputFile := filepath.Join(t.TempDir(), "prs.md")
+	if err := os.WriteFile(inputFile, []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+
+	summarizer := chatSummarizer{URL: server.URL, Model: "missing", Client: server.Client()}
+	_, err := summarizer.Summarize(context.Background(), SummaryRequest{Prompt: "Summarize.", InputFile: inputFile})
+	assert.ErrorContains(t, err, "model not found")
+}
+
+// funcSummarizer is the fake summarizer for tests: it runs a function instead of summarizing
+type funcSummarizer func(req SummaryRequest) (string, error)
+
+func (f funcSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	return f(req)
+}
+
+// inFlightCounter records how many calls of its summarizer are in flight at once
+type inFlightCounter struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	release     chan struct{}
+}
+
+// summarizer returns a summarizer whose calls each wait to be released
+func (c *inFlightCounter) summarizer() funcSummarizer {
+	return func(req SummaryRequest) (string, error) {
+		c.mu.Lock()
+		c.inFlight++
+		c.maxInFlight = max(c.maxInFlight, c.inFlight)
+		c.mu.Unlock()
+
+		<-c.release
+
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+		return "done", nil
+	}
+}
+
+func TestEchoSummarizer(t *testing.T) {
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}, MetadataFields: []string{"repository"}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", Title: "One", URL: "https://github.com/owner/repo/pull/1"},
+		{Repository: "owner/repo", Title: "Two", URL: "https://github.com/owner/repo/pull/2"},
+	}
+	path := filepath.Join(t.TempDir(), "prs.md")
+	assert.NoError(t, outputPRs(prs, nil, nil, path, config))
+
+	summary, err := echoSummarizer{}.Summarize(context.Background(), SummaryRequest{Prompt: "Summarize.", InputFile: path})
+	assert.NoError(t, err)
+	assert.Equal(t, "Echo summary of prs.md: 2 PRs, prompt of 10 characters, system prompt of 0 characters.", summary)
+}
+
+func TestLimitedSummarizer(t *testing.T) {
+	backend := &inFlightCounter{release: make(chan struct{})}
+	summarizer := newLimitedSummarizer(backend.summarizer(), 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summary, err := summarizer.Summarize(context.Background(), SummaryRequest{})
+			assert.NoError(t, err)
+			assert.Equal(t, "done", summary)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		backend.mu.Lock()
+		defer backend.mu.Unlock()
+		return backend.inFlight == 2
+	}, time.Second, time.Millisecond)
+	for i := 0; i < 5; i++ {
+		backend.release <- struct{}{}
+	}
+	wg.Wait()
+	assert.Equal(t, 2, backend.maxInFlight)
+
+	t.Run("gives up when the context is cancelled", func(t *testing.T) {
+		full := newLimitedSummarizer(backend.summarizer(), 1)
+		full.slots <- struct{}{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := full.Summarize(ctx, SummaryRequest{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestInputReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  string
+		expected string
+	}{
+		{"default backend", "", "@prs.md"},
+		{"copilot", summarizerCopilot, "@prs.md"},
+		{"chat", summarizerChat, inlinedInputReference},
+		{"echo", summarizerEcho, inlinedInputReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inputReference(tt.backend, "/tmp/out/prs.md"))
+		})
+	}
+
+	t.Run("prompt assembly", func(t *testing.T) {
+		var prompt string
+		summarizer := funcSummarizer(func(req SummaryRequest) (string, error) {
+			prompt = req.Prompt
+			return "summary", nil
+		})
+		_, err := generateSummary(context.Background(), summarizer, "/tmp/out/prs.md", defaultPrompt, Config{Summarizer: summarizerChat})
+		assert.NoError(t, err)
+		assert.NotContains(t, prompt, "@")
+		assert.Contains(t, prompt, "PR descriptions in the document below")
+
+		_, err = generateSummary(context.Background(), summarizer, "/tmp/out/prs.md", defaultPrompt, Config{Summarizer: summarizerCopilot})
+		assert.NoError(t, err)
+		assert.Contains(t, prompt, "PR descriptions in @prs.md")
+	})
+}
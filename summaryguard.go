@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileState is what guardedSummarizer remembers about a file to notice changes
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// guardedSummarizer fails a summarization if the wrapped summarizer created, modified,
+// or removed files in the output directory or the input file's directory. The prompts
+// tell the summarizer not to write files, but nothing else stops it.
+type guardedSummarizer struct {
+	backend Summarizer
+	dirs    []string
+}
+
+// newGuardedSummarizer wraps backend so that it may not change files under dirs, nor
+// next to the input file
+func newGuardedSummarizer(backend Summarizer, dirs ...string) *guardedSummarizer {
+	return &guardedSummarizer{backend: backend, dirs: dirs}
+}
+
+// Summarize implements Summarizer, comparing the watched directories before and after
+func (s *guardedSummarizer) Summarize(ctx context.Context, req SummaryRequest) (string, error) {
+	dirs := append([]string{filepath.Dir(req.InputFile)}, s.dirs...)
+
+	before, err := snapshotFiles(dirs)
+	if err != nil {
+		return "", err
+	}
+	summary, err := s.backend.Summarize(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	after, err := snapshotFiles(dirs)
+	if err != nil {
+		return "", err
+	}
+
+	if changes := diffFileStates(before, after); len(changes) > 0 {
+		return "", fmt.Errorf("summarizer changed files although told not to write any: %s", strings.Join(changes, ", "))
+	}
+	return summary, nil
+}
+
+// snapshotFiles records the state of every regular file under dirs. Directories that
+// don't exist are skipped.
+func snapshotFiles(dirs []string) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir && errors.Is(err, fs.ErrNotExist) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+		}
+	}
+	return files, nil
+}
+
+// diffFileStates describes the files created, modified, or removed between two
+// snapshots, sorted
+func diffFileStates(before, after map[string]fileState) []string {
+	var changes []string
+	for path, state := range after {
+		old, ok := before[path]
+		switch {
+		case !ok:
+			changes = append(changes, "created "+path)
+		case old.size != state.size || !old.modTime.Equal(state.modTime):
+			changes = append(changes, "modified "+path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, "removed "+path)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardedSummarizer(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(dir string) error
+		change string
+	}{
+		{"no changes", func(dir string) error { return nil }, ""},
+		{"creates a file", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "notes.md"), []byte("x"), 0644)
+		}, "created notes.md"},
+		{"modifies the input", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "prs.md"), []byte("rewritten"), 0644)
+		}, "modified prs.md"},
+		{"removes a file", func(dir string) error {
+			return os.Remove(filepath.Join(dir, "sub", "old.md"))
+		}, "removed sub/old.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			inputFile := filepath.Join(dir, "prs.md")
+			assert.NoError(t, os.WriteFile(inputFile, []byte("# PRs"), 0644))
+			assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "old.md"), []byte("old"), 0644))
+
+			summarizer := newGuardedSummarizer(funcSummarizer(func(req SummaryRequest) (string, error) {
+				return "summary", tt.action(dir)
+			}), filepath.Join(dir, "missing"))
+			summary, err := summarizer.Summarize(context.Background(), SummaryRequest{InputFile: inputFile})
+			if tt.change == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, "summary", summary)
+				return
+			}
+			assert.ErrorContains(t, err, "summarizer changed files although told not to write any")
+			verb, path, _ := strings.Cut(tt.change, " ")
+			assert.ErrorContains(t, err, verb+" "+filepath.Join(dir, filepath.FromSlash(path)))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// runSummaryOnly reruns the summarizer on the prs.md files left by an earlier run, for
+// iterating on prompts. Nothing is fetched from GitHub, and existing summaries (and brag
+// docs, with generate_brag_doc) are overwritten without asking.
+func runSummaryOnly(ctx context.Context, config Config, svc *services) error {
+	multiUser := len(config.Usernames) > 1
+	usernames := config.Usernames
+	if config.UsernameFromToken {
+		// The token's user isn't looked up without GitHub, but a single user's files are
+		// directly in output_dir anyway
+		usernames = []string{""}
+	}
+	var userConfigs []Config
+	for _, username := range usernames {
+		outputDir := config.OutputDir
+		if multiUser {
+			outputDir = filepath.Join(config.OutputDir, username)
+		}
+
+		userConfig := config
+		userConfig.Username = username
+		userConfig.OutputDir = outputDir
+		if err := requireEarlierRun(filepath.Join(outputDir, "prs.md")); err != nil {
+			return fmt.Errorf("failed to summarize PRs of user %s: %w", username, err)
+		}
+		userConfigs = append(userConfigs, userConfig)
+	}
+	if err := summarizeUsers(ctx, svc, userConfigs, summarizerWorkers(config)); err != nil {
+		return err
+	}
+
+	if config.CombineUsers && config.TeamSummary {
+		teamFile := filepath.Join(config.OutputDir, "team-report.md")
+		if err := resummarize(ctx, svc, teamFile, filepath.Join(config.OutputDir, "team-summary.md"), teamPrompt, config); err != nil {
+			return fmt.Errorf("failed to summarize team report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resummarize summarizes inputFile into outputFile, failing clearly if an earlier run
+// hasn't written inputFile
+func resummarize(ctx context.Context, svc *services, inputFile, outputFile, basePrompt string, config Config) error {
+	if err := requireEarlierRun(inputFile); err != nil {
+		return err
+	}
+
+	console.Infof("Generating summary of %s with %s...", inputFile, config.Summarizer)
+	return summarizeFile(ctx, svc, inputFile, outputFile, basePrompt, config)
+}
+
+// requireEarlierRun fails clearly if an earlier run hasn't written inputFile
+func requireEarlierRun(inputFile string) error {
+	if _, err := os.Stat(inputFile); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("-summary-only needs %s from an earlier run, but it doesn't exist; run without -summary-only to fetch the PRs first", inputFile)
+		}
+		return fmt.Errorf("cannot read %s: %w", inputFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSummaryOnly(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{Usernames: []string{"alice", "bob"}, OutputDir: dir, Repos: []string{"owner/repo"}, Summarizer: summarizerEcho}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	summarizer, err := newSummarizer(config)
+	assert.NoError(t, err)
+	// No GitHub client: anything that tried to fetch would fail
+	svc := newServices(context.Background(), summarizer, nil)
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(filepath.Join(dir, "alice", "prs.md"), "# Merged Pull Requests\n\nFound 2 merged pull requests.\n")
+	writeFile(filepath.Join(dir, "alice", "summary.md"), "old summary")
+
+	t.Run("missing prs.md", func(t *testing.T) {
+		err := runSummaryOnly(context.Background(), config, svc)
+		assert.ErrorContains(t, err, "user bob")
+		assert.ErrorContains(t, err, "run without -summary-only to fetch the PRs first")
+	})
+
+	t.Run("overwrites existing summaries", func(t *testing.T) {
+		writeFile(filepath.Join(dir, "bob", "prs.md"), "# Merged Pull Requests\n\nFound 1 merged pull requests.\n")
+		assert.NoError(t, runSummaryOnly(context.Background(), config, svc))
+
+		summary, err := os.ReadFile(filepath.Join(dir, "alice", "summary.md"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(summary), "Echo summary of prs.md: 2 PRs")
+
+		summary, err = os.ReadFile(filepath.Join(dir, "bob", "summary.md"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(summary), "Echo summary of prs.md: 1 PRs")
+	})
+}
+
+func TestSummaryToStdout(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{Username: "alice", OutputDir: dir, Repos: []string{"owner/repo"}, Summarizer: summarizerEcho, SummaryToStdout: true}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	summarizer, err := newSummarizer(config)
+	assert.NoError(t, err)
+	svc := newServices(context.Background(), summarizer, nil)
+
+	prsFile := filepath.Join(dir, "prs.md")
+	assert.NoError(t, os.WriteFile(prsFile, []byte("# Merged Pull Requests\n\nFound 1 merged pull requests.\n"), 0644))
+
+	// Capture stdout
+	reader, writer, err := os.Pipe()
+	assert.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	err = runSummaryOnly(context.Background(), config, svc)
+	os.Stdout = stdout
+	writer.Close()
+	assert.NoError(t, err)
+
+	output, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(output), "Echo summary of prs.md: 1 PRs,"), "only the summary, without a title: %q", output)
+	assert.Equal(t, 1, strings.Count(string(output), "\n"))
+	assert.NoFileExists(t, filepath.Join(dir, "summary.md"))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// outputTeamReport writes a single Markdown document covering every user in a
+// multi-user run, with an aggregate stats header and an H2 section per author. The
+// repositories are described from contexts (with include_repo_context).
+func outputTeamReport(reports []userReport, contexts map[string]repoContext, outputFile string, config Config) error {
+	writer, err := getOutputWriter(outputFile)
+	if err != nil {
+		return err
+	}
+	if outputFile != "" {
+		defer writer.Close()
+		console.Infof("Writing team report to %s", outputFile)
+	}
+
+	totalPRs := 0
+	totalOpen := 0
+	allRepos := make(map[string]bool)
+	for _, report := range reports {
+		totalPRs += len(report.PRs)
+		totalOpen += len(report.OpenPRs)
+		for _, pr := range report.PRs {
+			allRepos[pr.Repository] = true
+		}
+	}
+
+	// Write markdown header with team-level aggregates
+	fmt.Fprintf(writer, "# Team Report\n\n")
+	fmt.Fprintf(writer, "Found %d merged pull requests from %d authors across %d repositories.\n\n", totalPRs, len(reports), len(allRepos))
+	if config.IncludeOpenPRs {
+		fmt.Fprintf(writer, "Another %d pull requests are still open and are listed separately as in progress.\n\n", totalOpen)
+	}
+	if config.ContributionScore {
+		fmt.Fprintf(writer, "Contribution scores are a rough heuristic, not a measure of impact: %s.\n\n", scoreFormula(config.Weights))
+	}
+
+	// Open PRs are counted in their own column so they don't inflate the merged stats
+	columns := []string{"Author", "Merged PRs", "Repositories"}
+	if config.IncludeOpenPRs {
+		columns = append(columns, "Open PRs")
+	}
+	if config.ContributionScore {
+		columns = append(columns, "Contribution Score")
+	}
+	writeTableRow(writer, columns)
+	for _, column := range columns {
+		fmt.Fprintf(writer, "|%s", strings.Repeat("-", len(column)+2))
+	}
+	fmt.Fprintf(writer, "|\n")
+
+	for _, report := range reports {
+		repos := make(map[string]bool)
+		for _, pr := range report.PRs {
+			repos[pr.Repository] = true
+		}
+		row := []string{report.Username, fmt.Sprint(len(report.PRs)), fmt.Sprint(len(repos))}
+		if config.IncludeOpenPRs {
+			row = append(row, fmt.Sprint(len(report.OpenPRs)))
+		}
+		if config.ContributionScore {
+			row = append(row, fmt.Sprintf("%.1f", contributionScore(report.PRs, config.Weights)))
+		}
+		writeTableRow(writer, row)
+	}
+	fmt.Fprintf(writer, "\n")
+
+	var allPRs []PullRequestInfo
+	for _, report := range reports {
+		allPRs = append(allPRs, report.PRs...)
+	}
+	writeRepoContext(writer, allPRs, contexts, 2, config)
+
+	// Output each author's PRs nested beneath their own section
+	for _, report := range reports {
+		fmt.Fprintf(writer, "## %s\n\n", report.Username)
+
+		if len(report.PRs) == 0 {
+			fmt.Fprintf(writer, "%s\n\n", config.NoPRsText)
+		} else if err := writeRepoGroups(writer, report.PRs, 3, config); err != nil {
+			return err
+		}
+
+		if err := writeOpenPRs(writer, report.OpenPRs, 3, config); err != nil {
+			return err
+		}
+	}
+
+	return writer.Commit()
+}
+
+// writeTableRow writes one row of a Markdown table
+func writeTableRow(writer io.Writer, cells []string) {
+	fmt.Fprintf(writer, "| %s |\n", strings.Join(cells, " | "))
+}
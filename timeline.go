@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v56/github"
+)
+
+// timelineEventCrossReferenced is the timeline event for another issue or PR mentioning this one
+const timelineEventCrossReferenced = "cross-referenced"
+
+// annotateReverts sets RevertedBy on each PR that a later merged PR reverted. It reads
+// every PR's timeline, so it is only done with include_timeline.
+func annotateReverts(ctx context.Context, client *github.Client, prs []PullRequestInfo) {
+	for i := range prs {
+		repo, err := parseNWO(prs[i].Repository)
+		if err != nil || prs[i].Number == 0 {
+			continue
+		}
+
+		revertURL, err := findRevertingPR(ctx, client, repo, prs[i])
+		if err != nil {
+			console.Warnf("Failed to read timeline of %s: %v", prs[i].URL, err)
+			continue
+		}
+		prs[i].RevertedBy = revertURL
+	}
+}
+
+// findRevertingPR pages through a PR's timeline looking for a cross-reference from a
+// merged PR that reverts it, and returns that PR's URL, or "" if there is none
+func findRevertingPR(ctx context.Context, client *github.Client, repo NWO, pr PullRequestInfo) (string, error) {
+	opts := &github.ListOptions{PerPage: perPageLimit}
+
+	for {
+		events, resp, err := client.Issues.ListIssueTimeline(ctx, repo.Owner, repo.Name, pr.Number, opts)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range events {
+			if event.GetEvent() != timelineEventCrossReferenced || event.Source == nil {
+				continue
+			}
+			source := event.Source.Issue
+			if source == nil || !source.IsPullRequest() || !isRevertOf(source, pr) {
+				continue
+			}
+
+			// Only a merged revert actually reverted anything
+			sourceRepo := source.GetRepository()
+			owner, name := repo.Owner, repo.Name
+			if sourceRepo != nil {
+				owner, name = sourceRepo.GetOwner().GetLogin(), sourceRepo.GetName()
+			}
+			revert, _, err := client.PullRequests.Get(ctx, owner, name, source.GetNumber())
+			if err != nil {
+				return "", err
+			}
+			if revert.GetMerged() {
+				return source.GetHTMLURL(), nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return "", nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// isRevertOf reports whether a cross-referencing PR looks like a revert of pr, either by
+// the title GitHub's "Revert" button gives it or by its "Reverts owner/repo#N" body
+func isRevertOf(source *github.Issue, pr PullRequestInfo) bool {
+	if source.GetTitle() == fmt.Sprintf(`Revert "%s"`, pr.Title) {
+		return true
+	}
+
+	reference := fmt.Sprintf("Reverts %s#%d", pr.Repository, pr.Number)
+	for _, line := range strings.Split(source.GetBody(), "\n") {
+		if strings.TrimSpace(line) == reference {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRevertOf(t *testing.T) {
+	pr := PullRequestInfo{Repository: "owner/repo", Number: 42, Title: `Add "fast" mode`}
+
+	tests := []struct {
+		name     string
+		title    string
+		body     string
+		expected bool
+	}{
+		{name: "revert button title", title: `Revert "Add "fast" mode"`, expected: true},
+		{name: "revert button body", title: "Back out fast mode", body: "Reverts owner/repo#42\n\nIt broke prod.", expected: true},
+		{name: "other PR", title: "Reverts owner/repo#42 partially", body: "Follow-up to owner/repo#42", expected: false},
+		{name: "different number", title: "Revert", body: "Reverts owner/repo#421", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &github.Issue{Title: github.String(tt.title), Body: github.String(tt.body)}
+			assert.Equal(t, tt.expected, isRevertOf(source, pr))
+		})
+	}
+}
+
+func TestAnnotateReverts(t *testing.T) {
+	client := newTestGitHubClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/issues/1/timeline":
+			w.Write([]byte(`[
+				{"event": "labeled"},
+				{"event": "cross-referenced", "source": {"issue": {"number": 5, "title": "Mentions it", "html_url": "https://github.com/owner/repo/pull/5", "pull_request": {}}}},
+				{"event": "cross-referenced", "source": {"issue": {"number": 6, "title": "Revert \"First\"", "html_url": "https://github.com/owner/repo/pull/6", "pull_request": {}}}},
+				{"event": "cross-referenced", "source": {"issue": {"number": 7, "title": "Revert \"First\"", "html_url": "https://github.com/owner/repo/pull/7", "pull_request": {}}}}
+			]`))
+		case "/repos/owner/repo/issues/2/timeline":
+			w.Write([]byte(`[]`))
+		case "/repos/owner/repo/pulls/6":
+			w.Write([]byte(`{"number": 6, "merged": false}`))
+		case "/repos/owner/repo/pulls/7":
+			w.Write([]byte(`{"number": 7, "merged": true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	prs := []PullRequestInfo{
+		{Repository: "owner/repo", Number: 1, Title: "First", URL: "https://github.com/owner/repo/pull/1"},
+		{Repository: "owner/repo", Number: 2, Title: "Second", URL: "https://github.com/owner/repo/pull/2"},
+	}
+	annotateReverts(context.Background(), client, prs)
+
+	assert.Equal(t, "https://github.com/owner/repo/pull/7", prs[0].RevertedBy, "the unmerged revert is skipped")
+	assert.Empty(t, prs[1].RevertedBy)
+
+	config := Config{Username: "someone", OutputDir: "out", Repos: []string{"owner/repo"}}
+	if err := config.Parse(); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "prs.md")
+	assert.NoError(t, outputPRs(prs, nil, nil, path, config))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "### [First](https://github.com/owner/repo/pull/1) ⚠ later reverted")
+	assert.Contains(t, string(data), "| **Reverted by** | <https://github.com/owner/repo/pull/7> |")
+	assert.NotContains(t, string(data), "[Second](https://github.com/owner/repo/pull/2) ⚠")
+}
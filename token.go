@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// defaultGitHubHost is the host the token is for unless GH_HOST says otherwise
+	defaultGitHubHost = "github.com"
+
+	// fineGrainedTokenPrefix starts every fine-grained personal access token
+	fineGrainedTokenPrefix = "github_pat_"
+)
+
+// cachedToken is the on-disk form of a cached token
+type cachedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// tokenCache stores the gh token on disk for a short time, so that runs in quick
+// succession don't each shell out to gh
+type tokenCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// newTokenCache creates a token cache for host in the user cache directory
+func newTokenCache(host string, ttl time.Duration) (*tokenCache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	path := filepath.Join(dir, "employment-justifier", fmt.Sprintf("token-%s.json", host))
+	return &tokenCache{path: path, ttl: ttl, now: time.Now}, nil
+}
+
+// Load returns the cached token, or "" if there is none or it has expired
+func (c *tokenCache) Load() string {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return ""
+	}
+	var cached cachedToken
+	if err := json.Unmarshal(data, &cached); err != nil || !c.now().Before(cached.ExpiresAt) {
+		return ""
+	}
+	return cached.Token
+}
+
+// Store saves the token, readable only by the current user
+func (c *tokenCache) Store(token string) error {
+	data, err := json.Marshal(cachedToken{Token: token, ExpiresAt: c.now().Add(c.ttl)})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// Clear removes the cached token
+func (c *tokenCache) Clear() error {
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// tokenSource supplies the GitHub token, fetching it at most once per process unless it is
+// invalidated, and going through the disk cache first if there is one
+type tokenSource struct {
+	fetch func() (string, error)
+	cache *tokenCache // nil when disk caching is off
+
+	mu        sync.Mutex
+	token     string
+	fromCache bool
+}
+
+// githubHost returns the GitHub host gh is using: GH_HOST, or github.com by default
+func githubHost() string {
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return host
+	}
+	return defaultGitHubHost
+}
+
+// newGitHubClient creates the GitHub client every command uses. It authenticates with
+// tokens, retrying a request once with a fresh token if GitHub rejects a cached one, and
+// talks to host's API, which for anything but github.com is a GitHub Enterprise Server.
+// Requests go through base, or http.DefaultTransport if it is nil, which honors the usual
+// proxy (HTTPS_PROXY, NO_PROXY) and CA certificate (SSL_CERT_FILE) environment variables.
+func newGitHubClient(tokens *tokenSource, host string, base http.RoundTripper) (*github.Client, error) {
+	// Get the token up front so that not being logged in is reported before any API call
+	if _, err := tokens.Token(); err != nil {
+		return nil, withExitCode(exitAuth, fmt.Errorf("failed to get GitHub token: %w", err))
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport := &reauthTransport{
+		tokens: tokens,
+		base:   &oauth2.Transport{Source: tokens, Base: base},
+	}
+	client := github.NewClient(&http.Client{Transport: transport})
+
+	if host != defaultGitHubHost {
+		var err error
+		client, err = client.WithEnterpriseURLs("https://"+host+"/api/v3/", "https://"+host+"/api/uploads/")
+		if err != nil {
+			return nil, withExitCode(exitConfig, fmt.Errorf("invalid GitHub host '%s': %w", host, err))
+		}
+	}
+	return client, nil
+}
+
+// newTokenSource creates a token source that fetches with getGitHubToken. A ttl of zero
+// disables the disk cache.
+func newTokenSource(ttl time.Duration) *tokenSource {
+	source := &tokenSource{fetch: getGitHubToken}
+	if ttl > 0 {
+		cache, err := newTokenCache(githubHost(), ttl)
+		if err != nil {
+			console.Warnf("Not caching the GitHub token: %v", err)
+		} else {
+			source.cache = cache
+		}
+	}
+	return source
+}
+
+// Token implements oauth2.TokenSource
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" && s.cache != nil {
+		s.token = s.cache.Load()
+		s.fromCache = s.token != ""
+	}
+	if s.token == "" {
+		if err := s.fetchLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return &oauth2.Token{AccessToken: s.token}, nil
+}
+
+// Invalidate drops a token that GitHub rejected and fetches a fresh one. It reports
+// whether there is a new token worth retrying with, which is only the case when the
+// rejected one came from the disk cache.
+func (s *tokenSource) Invalidate() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.fromCache {
+		return false, nil
+	}
+	if err := s.cache.Clear(); err != nil {
+		console.Warnf("Failed to clear cached GitHub token: %v", err)
+	}
+	if err := s.fetchLocked(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fetchLocked gets a token from gh and caches it. s.mu must be held.
+func (s *tokenSource) fetchLocked() error {
+	token, err := s.fetch()
+	if err != nil {
+		return err
+	}
+	s.token = token
+	s.fromCache = false
+
+	if s.cache != nil {
+		if err := s.cache.Store(token); err != nil {
+			console.Warnf("Failed to cache GitHub token: %v", err)
+		}
+	}
+	return nil
+}
+
+// reauthTransport retries a request once with a fresh token when GitHub rejects a cached one
+type reauthTransport struct {
+	tokens *tokenSource
+	base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *reauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	refreshed, err := t.tokens.Invalidate()
+	if err != nil || !refreshed {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	console.Infof("Cached GitHub token was rejected; retrying with a fresh token from gh")
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// explainTokenAccessError adds a hint to an error GitHub returned for a request about repo
+// made with a fine-grained personal access token. Such tokens only see the repositories
+// they were granted, so a 403 or 404 (or a search GitHub refuses for lack of permission)
+// usually means the token lacks access rather than that the repository is missing.
+func explainTokenAccessError(err error, repo NWO) error {
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.Request == nil {
+		return err
+	}
+	switch respErr.Response.StatusCode {
+	case http.StatusForbidden, http.StatusNotFound:
+	case http.StatusUnprocessableEntity:
+		if !strings.Contains(strings.ToLower(respErr.Error()), "permission") {
+			return err
+		}
+	default:
+		return err
+	}
+
+	authorization := respErr.Response.Request.Header.Get("Authorization")
+	if !strings.HasPrefix(strings.TrimPrefix(authorization, "Bearer "), fineGrainedTokenPrefix) {
+		return err
+	}
+	return fmt.Errorf("%w (the GitHub token is a fine-grained personal access token, which may not have been granted access to %s/%s; check that its repository access includes it, with read access to pull requests and metadata)",
+		err, repo.Owner, repo.Name)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v56/github"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := &tokenCache{path: filepath.Join(t.TempDir(), "cache", "token-github.com.json"), ttl: 10 * time.Minute, now: func() time.Time { return now }}
+
+	assert.Empty(t, cache.Load(), "nothing cached yet")
+	assert.NoError(t, cache.Store("gho_abc"))
+	assert.Equal(t, "gho_abc", cache.Load())
+
+	info, err := os.Stat(cache.path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	now = now.Add(10 * time.Minute)
+	assert.Empty(t, cache.Load(), "expired")
+
+	assert.NoError(t, cache.Clear())
+	assert.NoError(t, cache.Clear(), "clearing twice is fine")
+}
+
+func TestTokenSource(t *testing.T) {
+	newSource := func(t *testing.T, cached string) (*tokenSource, *int) {
+		fetches := 0
+		cache := &tokenCache{path: filepath.Join(t.TempDir(), "token.json"), ttl: time.Hour, now: time.Now}
+		if cached != "" {
+			assert.NoError(t, cache.Store(cached))
+		}
+		source := &tokenSource{
+			fetch: func() (string, error) {
+				fetches++
+				return "fresh", nil
+			},
+			cache: cache,
+		}
+		return source, &fetches
+	}
+
+	t.Run("uses the cache before gh", func(t *testing.T) {
+		source, fetches := newSource(t, "cached")
+		token, err := source.Token()
+		assert.NoError(t, err)
+		assert.Equal(t, "cached", token.AccessToken)
+		assert.Equal(t, 0, *fetches)
+	})
+
+	t.Run("fetches once and caches", func(t *testing.T) {
+		source, fetches := newSource(t, "")
+		for i := 0; i < 2; i++ {
+			token, err := source.Token()
+			assert.NoError(t, err)
+			assert.Equal(t, "fresh", token.AccessToken)
+		}
+		assert.Equal(t, 1, *fetches)
+		assert.Equal(t, "fresh", source.cache.Load())
+	})
+
+	t.Run("invalidating a cached token refetches", func(t *testing.T) {
+		source, fetches := newSource(t, "stale")
+		_, err := source.Token()
+		assert.NoError(t, err)
+
+		refreshed, err := source.Invalidate()
+		assert.NoError(t, err)
+		assert.True(t, refreshed)
+		assert.Equal(t, 1, *fetches)
+
+		refreshed, err = source.Invalidate()
+		assert.NoError(t, err)
+		assert.False(t, refreshed, "a token straight from gh is not retried")
+	})
+}
+
+func TestReauthTransport(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		if auth != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cache := &tokenCache{path: filepath.Join(t.TempDir(), "token.json"), ttl: time.Hour, now: time.Now}
+	assert.NoError(t, cache.Store("stale"))
+	source := &tokenSource{fetch: func() (string, error) { return "fresh", nil }, cache: cache}
+	client := &http.Client{Transport: &reauthTransport{tokens: source, base: &oauth2.Transport{Source: source, Base: http.DefaultTransport}}}
+
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"Bearer stale", "Bearer fresh"}, seen)
+	assert.Equal(t, "fresh", cache.Load())
+}
+
+func TestExplainTokenAccessError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/private/pulls/1":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		case "/search/issues":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"message": "Validation Failed", "errors": [{"message": "The listed users and repositories cannot be searched either because the resources do not exist or you do not have permission to view them."}]}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message": "Server Error"}`))
+		}
+	})
+
+	repo := NWO{Owner: "owner", Name: "private"}
+	tests := []struct {
+		name    string
+		token   string
+		request func(client *github.Client) error
+		hinted  bool
+	}{
+		{"fine-grained 404", "github_pat_abc", func(client *github.Client) error {
+			_, _, err := client.PullRequests.Get(context.Background(), "owner", "private", 1)
+			return err
+		}, true},
+		{"fine-grained search without permission", "github_pat_abc", func(client *github.Client) error {
+			_, _, err := client.Search.Issues(context.Background(), "repo:owner/private", nil)
+			return err
+		}, true},
+		{"classic token 404", "gho_abc", func(client *github.Client) error {
+			_, _, err := client.PullRequests.Get(context.Background(), "owner", "private", 1)
+			return err
+		}, false},
+		{"fine-grained server error", "github_pat_abc", func(client *github.Client) error {
+			_, _, err := client.PullRequests.Get(context.Background(), "owner", "private", 2)
+			return err
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestGitHubClient(t, handler).WithAuthToken(tt.token)
+
+			err := tt.request(client)
+			assert.Error(t, err)
+			explained := explainTokenAccessError(err, repo)
+			assert.ErrorIs(t, explained, err)
+			if tt.hinted {
+				assert.ErrorContains(t, explained, "fine-grained personal access token, which may not have been granted access to owner/private")
+			} else {
+				assert.Equal(t, err, explained)
+			}
+		})
+	}
+}
+
+// transportFunc adapts a function to http.RoundTripper
+type transportFunc func(req *http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewGitHubClient(t *testing.T) {
+	var requests []*http.Request
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		recorder := httptest.NewRecorder()
+		recorder.Write([]byte(`{"login": "octocat"}`))
+		return recorder.Result(), nil
+	})
+	tokens := &tokenSource{fetch: func() (string, error) { return "gho_abc", nil }}
+
+	tests := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{"github.com", "github.com", "https://api.github.com/user"},
+		{"enterprise server", "ghe.example.com", "https://ghe.example.com/api/v3/user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			client, err := newGitHubClient(tokens, tt.host, transport)
+			assert.NoError(t, err)
+
+			user, _, err := client.Users.Get(context.Background(), "")
+			assert.NoError(t, err)
+			assert.Equal(t, "octocat", user.GetLogin())
+			if assert.Len(t, requests, 1) {
+				assert.Equal(t, tt.expected, requests[0].URL.String())
+				assert.Equal(t, "Bearer gho_abc", requests[0].Header.Get("Authorization"))
+			}
+		})
+	}
+
+	t.Run("no token", func(t *testing.T) {
+		tokens := &tokenSource{fetch: func() (string, error) { return "", os.ErrNotExist }}
+		_, err := newGitHubClient(tokens, "github.com", transport)
+		assert.ErrorContains(t, err, "failed to get GitHub token")
+		assert.Equal(t, exitAuth, exitCodeFor(err))
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+)
+
+const (
+	// Values for the -color flag
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+)
+
+// consolePrinter formats status messages written to stderr, colorizing warnings and
+// errors only when color is enabled so that CI logs stay plain
+type consolePrinter struct {
+	color bool
+}
+
+// console is the process-wide status printer, configured from the -color flag in main
+var console = &consolePrinter{}
+
+// resolveColor decides whether to emit ANSI color codes for the given -color mode.
+// In auto mode color is used only when stderr is a terminal and NO_COLOR is unset.
+func resolveColor(mode string, isTerminal bool, noColorEnv bool) (bool, error) {
+	switch mode {
+	case colorAlways:
+		return true, nil
+	case colorNever:
+		return false, nil
+	case colorAuto, "":
+		return isTerminal && !noColorEnv, nil
+	default:
+		return false, fmt.Errorf("invalid color mode '%s': expected '%s', '%s', or '%s'", mode, colorAuto, colorAlways, colorNever)
+	}
+}
+
+// stderrIsTerminal reports whether stderr is attached to a terminal
+func stderrIsTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// paint wraps s in the given ANSI color when color is enabled
+func (p *consolePrinter) paint(color, s string) string {
+	if !p.color {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+// Infof logs a status message
+func (p *consolePrinter) Infof(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+// Warnf logs a message prefixed with "Warning:"
+func (p *consolePrinter) Warnf(format string, args ...interface{}) {
+	log.Printf("%s %s", p.paint(ansiYellow, "Warning:"), fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a message prefixed with "Error:" without exiting
+func (p *consolePrinter) Errorf(format string, args ...interface{}) {
+	log.Printf("%s %s", p.paint(ansiRed, "Error:"), fmt.Sprintf(format, args...))
+}
+
+// newProgressBar creates the PR progress bar, using color and in-place redraws only
+// when color is enabled
+func (p *consolePrinter) newProgressBar(total int, description string) *progressbar.ProgressBar {
+	options := []progressbar.Option{
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionSetRenderBlankState(true),
+		progressbar.OptionEnableColorCodes(p.color),
+		progressbar.OptionUseANSICodes(p.color),
+	}
+	if p.color {
+		options = append(options, progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}))
+	}
+	return progressbar.NewOptions(total, options...)
+}
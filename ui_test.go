@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		isTerminal bool
+		noColorEnv bool
+		expected   bool
+		expectErr  bool
+	}{
+		{name: "auto on terminal", mode: colorAuto, isTerminal: true, expected: true},
+		{name: "auto off terminal", mode: colorAuto, isTerminal: false, expected: false},
+		{name: "auto respects NO_COLOR", mode: colorAuto, isTerminal: true, noColorEnv: true, expected: false},
+		{name: "empty mode behaves like auto", mode: "", isTerminal: true, expected: true},
+		{name: "always off terminal", mode: colorAlways, isTerminal: false, noColorEnv: true, expected: true},
+		{name: "never on terminal", mode: colorNever, isTerminal: true, expected: false},
+		{name: "invalid mode", mode: "sometimes", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveColor(tt.mode, tt.isTerminal, tt.noColorEnv)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestConsolePrinterPaint(t *testing.T) {
+	assert.Equal(t, "Warning:", (&consolePrinter{color: false}).paint(ansiYellow, "Warning:"))
+	assert.Equal(t, ansiYellow+"Warning:"+ansiReset, (&consolePrinter{color: true}).paint(ansiYellow, "Warning:"))
+}